@@ -14,19 +14,69 @@ type payload struct {
 type accumulator struct {
 	slog.Handler
 	last *payload
+
+	// attrs is the assembled tree of attributes and groups,
+	// cached on WithAttrs and WithGroup calls
+	attrs []slog.Attr
+}
+
+type handlerAttrsKey struct{}
+
+type handlerAttrs struct {
+	attrs    []slog.Attr
+	callSite int // number of call-site attributes in the record
 }
 
 // Accumulator is a wrapper for slog.Handler that accumulates
 // attributes and groups and passes them to the underlying handler
 // only on Handle call, instead of logging them immediately.
+//
+// Accumulated attributes are added to the end of the record, after
+// the call-site ones, and are available to the underlying handler (and
+// middlewares) with HandlerAttrs, while the call-site attributes are
+// available with CallSiteAttrs.
 func Accumulator(h slog.Handler) slog.Handler {
 	return &accumulator{Handler: h}
 }
 
-// Handle accumulates attributes and groups and then calls the wrapped handler.
+// HandlerAttrs returns the attributes and groups, accumulated by Accumulator
+// from the WithAttrs and WithGroup calls, for the record being handled.
+func HandlerAttrs(ctx context.Context) []slog.Attr {
+	v, _ := ctx.Value(handlerAttrsKey{}).(handlerAttrs)
+	return v.attrs
+}
+
+// CallSiteAttrs returns the attributes of the record, that were passed
+// at the call site, i.e. without the ones added by Accumulator and the
+// ones added to the end of the record by middlewares after it.
+// If the record is not handled through Accumulator, all of its attributes
+// are returned.
+func CallSiteAttrs(ctx context.Context, rec slog.Record) []slog.Attr {
+	v, ok := ctx.Value(handlerAttrsKey{}).(handlerAttrs)
+	if !ok {
+		return Attrs(rec)
+	}
+
+	attrs := make([]slog.Attr, 0, v.callSite)
+	rec.Attrs(func(attr slog.Attr) bool {
+		if len(attrs) == v.callSite {
+			return false
+		}
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
+
+// Handle adds the accumulated attributes and groups to the record
+// and then calls the wrapped handler.
 func (a *accumulator) Handle(ctx context.Context, rec slog.Record) error {
-	if a.last != nil {
-		rec.AddAttrs(a.assemble()...)
+	if len(a.attrs) > 0 {
+		ctx = context.WithValue(ctx, handlerAttrsKey{}, handlerAttrs{
+			attrs:    a.attrs,
+			callSite: rec.NumAttrs(),
+		})
+		rec.AddAttrs(a.attrs...)
 	}
 	return a.Handler.Handle(ctx, rec)
 }
@@ -34,10 +84,13 @@ func (a *accumulator) Handle(ctx context.Context, rec slog.Record) error {
 // WithAttrs returns a new accumulator with the given attributes.
 func (a *accumulator) WithAttrs(attrs []slog.Attr) slog.Handler {
 	acc := *a // shallow copy
-	if acc.last == nil {
-		acc.last = &payload{}
+	acc.last = &payload{}
+	if a.last != nil {
+		*acc.last = *a.last
 	}
-	acc.last.attrs = append(acc.last.attrs, attrs...)
+	// copy attributes to not share the backing array with the siblings
+	acc.last.attrs = append(acc.last.attrs[:len(acc.last.attrs):len(acc.last.attrs)], attrs...)
+	acc.attrs = acc.assemble()
 	return &acc
 }
 
@@ -45,12 +98,13 @@ func (a *accumulator) WithAttrs(attrs []slog.Attr) slog.Handler {
 func (a *accumulator) WithGroup(group string) slog.Handler {
 	acc := *a // shallow copy
 	acc.last = &payload{group: group, parent: acc.last}
+	acc.attrs = acc.assemble()
 	return &acc
 }
 
 func (a *accumulator) assemble() (attrs []slog.Attr) {
 	for p := a.last; p != nil; p = p.parent {
-		attrs = append(p.attrs, attrs...)
+		attrs = append(p.attrs[:len(p.attrs):len(p.attrs)], attrs...)
 		if p.group != "" {
 			attrs = []slog.Attr{slog.Group(p.group, listAny(attrs)...)}
 		}
@@ -79,3 +79,58 @@ func TestAccumulator_Handle(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestAccumulator_Siblings(t *testing.T) {
+	var got [][]slog.Attr
+	acc := Accumulator(slogt.HandlerFunc(func(ctx context.Context, rec slog.Record) error {
+		got = append(got, Attrs(rec))
+		return nil
+	}))
+
+	parent := acc.WithAttrs([]slog.Attr{slog.String("a", "1")})
+	first := parent.WithAttrs([]slog.Attr{slog.String("b", "2")})
+	second := parent.WithAttrs([]slog.Attr{slog.String("c", "3")})
+
+	require.NoError(t, first.Handle(context.Background(), slog.Record{}))
+	require.NoError(t, second.Handle(context.Background(), slog.Record{}))
+	require.NoError(t, parent.Handle(context.Background(), slog.Record{}))
+
+	assert.Equal(t, [][]slog.Attr{
+		{slog.String("a", "1"), slog.String("b", "2")},
+		{slog.String("a", "1"), slog.String("c", "3")},
+		{slog.String("a", "1")},
+	}, got)
+}
+
+func TestHandlerAttrs(t *testing.T) {
+	var handlerAttrs, callSiteAttrs []slog.Attr
+	lg := slog.New(Accumulator(NewChain(nopHandlerFunc(), func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error {
+			rec.AddAttrs(slog.String("added", "by middleware"))
+			handlerAttrs = HandlerAttrs(ctx)
+			callSiteAttrs = CallSiteAttrs(ctx, rec)
+			return next(ctx, rec)
+		}
+	})))
+
+	lg.With(slog.String("a", "1")).WithGroup("g").With(slog.String("b", "2")).
+		Info("test", slog.String("c", "3"))
+
+	assert.Equal(t, []slog.Attr{
+		slog.String("a", "1"),
+		slog.Group("g", slog.String("b", "2")),
+	}, handlerAttrs)
+	assert.Equal(t, []slog.Attr{slog.String("c", "3")}, callSiteAttrs)
+
+	t.Run("without accumulator", func(t *testing.T) {
+		rec := slog.Record{}
+		rec.AddAttrs(slog.String("a", "1"))
+		assert.Empty(t, HandlerAttrs(context.Background()))
+		assert.Equal(t, []slog.Attr{slog.String("a", "1")}, CallSiteAttrs(context.Background(), rec))
+	})
+}
+
+// nopHandlerFunc returns an enabled handler, that does nothing.
+func nopHandlerFunc() slog.Handler {
+	return slogt.HandlerFunc(func(context.Context, slog.Record) error { return nil })
+}
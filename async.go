@@ -0,0 +1,300 @@
+package slogx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed is returned by handlers, that were already closed.
+var ErrClosed = errors.New("handler is closed")
+
+// OverflowPolicy specifies what the AsyncHandler does with a record,
+// when its queue is full.
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock blocks the caller until there is space in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the record that is about to be queued.
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest record in the queue to
+	// make space for the new one.
+	OverflowDropOldest
+	// OverflowDropBelow drops the record that is about to be queued
+	// if its level is below the configured one, and blocks otherwise.
+	// See AsyncDropBelow.
+	OverflowDropBelow
+)
+
+const defaultAsyncQueueSize = 1024
+
+type asyncOptions struct {
+	queueSize int
+	policy    OverflowPolicy
+	dropLevel slog.Level
+	onError   func(error)
+}
+
+// AsyncOption is a functional option for Async.
+type AsyncOption func(*asyncOptions)
+
+// AsyncQueueSize sets the maximum amount of records waiting to be handled,
+// zero means that each record waits for the background goroutine to take it.
+// Default is 1024, it is also used, if n is negative.
+func AsyncQueueSize(n int) AsyncOption { return func(o *asyncOptions) { o.queueSize = n } }
+
+// AsyncOverflow sets the policy to apply when the queue is full.
+// Default is OverflowBlock.
+func AsyncOverflow(p OverflowPolicy) AsyncOption { return func(o *asyncOptions) { o.policy = p } }
+
+// AsyncDropBelow sets the OverflowDropBelow policy with the given level,
+// i.e. when the queue is full, records below lvl are dropped, while the
+// rest of them wait for the space in the queue.
+func AsyncDropBelow(lvl slog.Level) AsyncOption {
+	return func(o *asyncOptions) {
+		o.policy = OverflowDropBelow
+		o.dropLevel = lvl
+	}
+}
+
+// AsyncOnError sets the function to call with errors returned by the
+// wrapped handler. By default, errors are ignored.
+func AsyncOnError(fn func(error)) AsyncOption { return func(o *asyncOptions) { o.onError = fn } }
+
+// AsyncHandler is a handler that passes records to the wrapped handler
+// from a background goroutine, through a bounded queue.
+// Amount of dropped records (if any) is reported to the wrapped handler
+// as a separate warning record.
+// Handlers, derived from it with WithAttrs and WithGroup, share the same
+// queue, so Flush and Close may be called on any of them.
+type AsyncHandler struct {
+	h    slog.Handler
+	core *asyncCore
+}
+
+type asyncItem struct {
+	ctx context.Context
+	h   slog.Handler
+	rec slog.Record
+}
+
+type asyncCore struct {
+	opts    asyncOptions
+	root    slog.Handler
+	items   chan asyncItem
+	flushes chan chan struct{}
+	dropped atomic.Uint64
+
+	mu      sync.RWMutex // guards closed and adding to pushing
+	closed  bool
+	pushing sync.WaitGroup // pushes in progress, that may still send to items
+	quit    chan struct{}
+	stopped chan struct{}
+}
+
+// Async wraps the handler to handle records asynchronously.
+// Caller must call Close to flush the queue and stop the background goroutine.
+func Async(h slog.Handler, opts ...AsyncOption) *AsyncHandler {
+	o := asyncOptions{queueSize: defaultAsyncQueueSize, onError: func(error) {}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.queueSize < 0 {
+		o.queueSize = defaultAsyncQueueSize
+	}
+
+	c := &asyncCore{
+		opts:    o,
+		root:    h,
+		items:   make(chan asyncItem, o.queueSize),
+		flushes: make(chan chan struct{}),
+		quit:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go c.run()
+
+	return &AsyncHandler{h: h, core: c}
+}
+
+// Enabled returns true if the wrapped handler is enabled for the level.
+func (a *AsyncHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return a.h.Enabled(ctx, lvl)
+}
+
+// Handle queues the clone of the record.
+// Context is detached from its parent's cancellation, as the record
+// may be handled after the caller returns.
+func (a *AsyncHandler) Handle(ctx context.Context, rec slog.Record) error {
+	it := asyncItem{ctx: context.WithoutCancel(ctx), h: a.h, rec: rec.Clone()}
+	return a.core.push(it)
+}
+
+// WithAttrs returns a new AsyncHandler with the given attributes,
+// that shares the queue with the parent.
+func (a *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{h: a.h.WithAttrs(attrs), core: a.core}
+}
+
+// WithGroup returns a new AsyncHandler with the given group,
+// that shares the queue with the parent.
+func (a *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{h: a.h.WithGroup(name), core: a.core}
+}
+
+// Flush waits until all the records, queued before the call, are handled.
+func (a *AsyncHandler) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	select {
+	case a.core.flushes <- done:
+	case <-a.core.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new records and waits until the queued ones,
+// including the ones of the producers, blocked on the full queue,
+// are handled, or until the context is done.
+// Records, passed to Handle after Close, are rejected with ErrClosed.
+func (a *AsyncHandler) Close(ctx context.Context) error {
+	a.core.mu.Lock()
+	if !a.core.closed {
+		a.core.closed = true
+		close(a.core.quit)
+	}
+	a.core.mu.Unlock()
+
+	select {
+	case <-a.core.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *asyncCore) push(it asyncItem) error {
+	// the lock is not held while sending, as the send may block,
+	// so Close doesn't wait for the blocked producers
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return ErrClosed
+	}
+	c.pushing.Add(1)
+	c.mu.RUnlock()
+	defer c.pushing.Done()
+
+	switch {
+	case c.opts.policy == OverflowDropNewest,
+		c.opts.policy == OverflowDropBelow && it.rec.Level < c.opts.dropLevel:
+		select {
+		case c.items <- it:
+		default:
+			c.dropped.Add(1)
+		}
+	case c.opts.policy == OverflowDropOldest:
+		for {
+			select {
+			case c.items <- it:
+				return nil
+			default:
+			}
+
+			select {
+			case <-c.items:
+				c.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		c.items <- it
+	}
+
+	return nil
+}
+
+func (c *asyncCore) run() {
+	defer close(c.stopped)
+
+	for {
+		select {
+		case it := <-c.items:
+			c.handle(it)
+		case done := <-c.flushes:
+			c.drain()
+			close(done)
+		case <-c.quit:
+			c.drainPushing()
+			return
+		}
+	}
+}
+
+// drainPushing handles the records until the pushes, which started
+// before Close, are done, and then the rest of the queue.
+func (c *asyncCore) drainPushing() {
+	pushed := make(chan struct{})
+	go func() {
+		c.pushing.Wait()
+		close(pushed)
+	}()
+
+	for {
+		select {
+		case it := <-c.items:
+			c.handle(it)
+		case <-pushed:
+			c.drain()
+			return
+		}
+	}
+}
+
+// drain handles all the records that are currently in the queue.
+func (c *asyncCore) drain() {
+	for {
+		select {
+		case it := <-c.items:
+			c.handle(it)
+		default:
+			c.reportDropped()
+			return
+		}
+	}
+}
+
+func (c *asyncCore) handle(it asyncItem) {
+	c.reportDropped()
+	if err := it.h.Handle(it.ctx, it.rec); err != nil {
+		c.opts.onError(err)
+	}
+}
+
+func (c *asyncCore) reportDropped() {
+	n := c.dropped.Swap(0)
+	if n == 0 || !c.root.Enabled(context.Background(), slog.LevelWarn) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+
+	rec := slog.NewRecord(time.Now(), slog.LevelWarn, "[slogx.Async] records dropped", pcs[0])
+	rec.AddAttrs(slog.Uint64("dropped", n))
+	if err := c.root.Handle(context.Background(), rec); err != nil {
+		c.opts.onError(err)
+	}
+}
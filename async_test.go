@@ -0,0 +1,251 @@
+package slogx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx/slogt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler blocks on the first record until released.
+type blockingHandler struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+
+	mu   sync.Mutex
+	msgs []string
+	recs []slog.Record
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (b *blockingHandler) handler() slog.Handler {
+	return slogt.HandlerFunc(func(_ context.Context, rec slog.Record) error {
+		b.once.Do(func() {
+			close(b.started)
+			<-b.release
+		})
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.msgs = append(b.msgs, rec.Message)
+		b.recs = append(b.recs, rec)
+		return nil
+	})
+}
+
+func (b *blockingHandler) messages() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.msgs...)
+}
+
+func TestAsync_Handle(t *testing.T) {
+	t.Run("records are handled in order", func(t *testing.T) {
+		bh := newBlockingHandler()
+		close(bh.release)
+
+		h := Async(bh.handler())
+		lg := slog.New(h)
+		lg.Info("first")
+		lg.With(slog.String("a", "1")).Info("second")
+		lg.WithGroup("g").Info("third")
+
+		require.NoError(t, h.Flush(context.Background()))
+		assert.Equal(t, []string{"first", "second", "third"}, bh.messages())
+		require.NoError(t, h.Close(context.Background()))
+	})
+
+	t.Run("record is cloned", func(t *testing.T) {
+		bh := newBlockingHandler()
+		close(bh.release)
+
+		h := Async(bh.handler())
+		rec := slog.NewRecord(time.Now(), slog.LevelInfo, "test", 0)
+		rec.AddAttrs(slog.String("a", "1"))
+		require.NoError(t, h.Handle(context.Background(), rec))
+		rec.AddAttrs(slog.String("b", "2"))
+		require.NoError(t, h.Close(context.Background()))
+
+		require.Len(t, bh.recs, 1)
+		assert.Equal(t, []slog.Attr{slog.String("a", "1")}, Attrs(bh.recs[0]))
+	})
+
+	t.Run("handler errors", func(t *testing.T) {
+		var errs []error
+		h := Async(
+			slogt.HandlerFunc(func(context.Context, slog.Record) error { return errors.New("failed") }),
+			AsyncOnError(func(err error) { errs = append(errs, err) }),
+		)
+		slog.New(h).Info("test")
+		require.NoError(t, h.Close(context.Background()))
+		require.Len(t, errs, 1)
+		assert.EqualError(t, errs[0], "failed")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		h := Async(NopHandler())
+		require.NoError(t, h.Close(context.Background()))
+		require.NoError(t, h.Close(context.Background()), "second close must not fail")
+		require.NoError(t, h.Flush(context.Background()), "flush after close must not fail")
+		assert.ErrorIs(t, h.Handle(context.Background(), slog.Record{}), ErrClosed)
+	})
+}
+
+func TestAsync_Overflow(t *testing.T) {
+	// fill pushes "blocker" which stalls the worker, and then
+	// fills the queue of size 2 with "q1" and "q2"
+	fill := func(t *testing.T, opts ...AsyncOption) (*AsyncHandler, *blockingHandler) {
+		bh := newBlockingHandler()
+		h := Async(bh.handler(), append([]AsyncOption{AsyncQueueSize(2)}, opts...)...)
+		push(t, h, slog.LevelInfo, "blocker")
+		<-bh.started
+		push(t, h, slog.LevelInfo, "q1")
+		push(t, h, slog.LevelInfo, "q2")
+		return h, bh
+	}
+
+	t.Run("drop newest", func(t *testing.T) {
+		h, bh := fill(t, AsyncOverflow(OverflowDropNewest))
+		push(t, h, slog.LevelError, "new")
+		close(bh.release)
+		require.NoError(t, h.Close(context.Background()))
+
+		assert.Equal(t, []string{"blocker", "[slogx.Async] records dropped", "q1", "q2"}, bh.messages())
+		assert.Equal(t, []slog.Attr{slog.Uint64("dropped", 1)}, Attrs(bh.recs[1]))
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		h, bh := fill(t, AsyncOverflow(OverflowDropOldest))
+		push(t, h, slog.LevelInfo, "new1")
+		push(t, h, slog.LevelInfo, "new2")
+		close(bh.release)
+		require.NoError(t, h.Close(context.Background()))
+
+		assert.Equal(t, []string{"blocker", "[slogx.Async] records dropped", "new1", "new2"}, bh.messages())
+		assert.Equal(t, []slog.Attr{slog.Uint64("dropped", 2)}, Attrs(bh.recs[1]))
+	})
+
+	t.Run("drop below", func(t *testing.T) {
+		h, bh := fill(t, AsyncDropBelow(slog.LevelWarn))
+		push(t, h, slog.LevelInfo, "info")
+
+		errPushed := make(chan struct{})
+		go func() {
+			push(t, h, slog.LevelError, "error")
+			close(errPushed)
+		}()
+
+		select {
+		case <-errPushed:
+			t.Fatal("error record must wait for the space in the queue")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(bh.release)
+		<-errPushed
+		require.NoError(t, h.Close(context.Background()))
+
+		assert.Equal(t, []string{"blocker", "[slogx.Async] records dropped", "q1", "q2", "error"}, bh.messages())
+	})
+
+	t.Run("block", func(t *testing.T) {
+		h, bh := fill(t)
+
+		pushed := make(chan struct{})
+		go func() {
+			push(t, h, slog.LevelDebug, "new")
+			close(pushed)
+		}()
+
+		select {
+		case <-pushed:
+			t.Fatal("record must wait for the space in the queue")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(bh.release)
+		<-pushed
+		require.NoError(t, h.Close(context.Background()))
+		assert.Equal(t, []string{"blocker", "q1", "q2", "new"}, bh.messages())
+	})
+}
+
+func TestAsync_FlushTimeout(t *testing.T) {
+	bh := newBlockingHandler()
+	h := Async(bh.handler())
+	push(t, h, slog.LevelInfo, "blocker")
+	<-bh.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, h.Flush(ctx), context.DeadlineExceeded)
+
+	close(bh.release)
+	require.NoError(t, h.Flush(context.Background()))
+	require.NoError(t, h.Close(context.Background()))
+	assert.Equal(t, []string{"blocker"}, bh.messages())
+}
+
+func TestAsync_QueueSize(t *testing.T) {
+	for _, n := range []int{-1, 0} {
+		bh := newBlockingHandler()
+		close(bh.release)
+
+		h := Async(bh.handler(), AsyncQueueSize(n))
+		push(t, h, slog.LevelInfo, "message")
+		require.NoError(t, h.Close(context.Background()))
+		assert.Equal(t, []string{"message"}, bh.messages())
+	}
+
+	h := Async(slog.DiscardHandler, AsyncQueueSize(-1))
+	assert.Equal(t, defaultAsyncQueueSize, cap(h.core.items))
+	require.NoError(t, h.Close(context.Background()))
+}
+
+func push(t *testing.T, h slog.Handler, lvl slog.Level, msg string) {
+	require.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), lvl, msg, 0)))
+}
+
+func TestAsync_CloseWithBlockedProducer(t *testing.T) {
+	bh := newBlockingHandler()
+	h := Async(bh.handler(), AsyncQueueSize(1))
+	push(t, h, slog.LevelInfo, "blocker")
+	<-bh.started
+	push(t, h, slog.LevelInfo, "queued")
+
+	pushed := make(chan struct{})
+	go func() {
+		push(t, h, slog.LevelInfo, "blocked")
+		close(pushed)
+	}()
+	time.Sleep(20 * time.Millisecond) // let the producer block on the full queue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	closed := make(chan error)
+	go func() { closed <- h.Close(ctx) }()
+
+	select {
+	case err := <-closed:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("Close must return when its context is done")
+	}
+
+	assert.ErrorIs(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "late", 0)), ErrClosed)
+
+	close(bh.release)
+	<-pushed
+	require.NoError(t, h.Close(context.Background()))
+	assert.Equal(t, []string{"blocker", "queued", "blocked"}, bh.messages())
+}
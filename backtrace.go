@@ -0,0 +1,246 @@
+package slogx
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+)
+
+type backtraceOptions struct {
+	keyFn      func(context.Context) (string, bool)
+	trigger    slog.Leveler
+	lvl        slog.Leveler
+	size       int
+	maxBytes   int
+	maxBuffers int
+}
+
+// BacktraceOption is a functional option for Backtrace.
+type BacktraceOption func(*backtraceOptions)
+
+// BacktraceKey sets the function to get the key of the buffer from the
+// context, e.g. slogm.RequestIDFromContext. Records, for which the key is
+// not found, are kept in the global buffer. By default, all records are
+// kept in the global buffer.
+func BacktraceKey(fn func(context.Context) (string, bool)) BacktraceOption {
+	return func(o *backtraceOptions) { o.keyFn = fn }
+}
+
+// BacktraceTrigger sets the level of records, that trigger flushing
+// of the buffer. Default is slog.LevelError.
+func BacktraceTrigger(lvl slog.Leveler) BacktraceOption {
+	return func(o *backtraceOptions) { o.trigger = lvl }
+}
+
+// BacktraceLevel sets the minimum level of records to keep in the buffer,
+// if they are not enabled by the wrapped handler. Default is slog.LevelDebug.
+func BacktraceLevel(lvl slog.Leveler) BacktraceOption {
+	return func(o *backtraceOptions) { o.lvl = lvl }
+}
+
+// BacktraceSize sets the maximum amount of records in each buffer,
+// the oldest records are dropped first. Default is 100.
+func BacktraceSize(n int) BacktraceOption {
+	return func(o *backtraceOptions) { o.size = n }
+}
+
+// BacktraceMaxBytes sets the maximum approximate size of records in each
+// buffer, the oldest records are dropped first. The size of the record is
+// the length of its message, keys and string values, and 8 bytes for each
+// other value. By default, the size is not limited.
+func BacktraceMaxBytes(n int) BacktraceOption {
+	return func(o *backtraceOptions) { o.maxBytes = n }
+}
+
+// BacktraceMaxBuffers sets the maximum amount of buffers, the least recently
+// used buffers are dropped first. Along with BacktraceSize and
+// BacktraceMaxBytes, it limits the memory, consumed by the handler.
+// Default is 1024.
+func BacktraceMaxBuffers(n int) BacktraceOption {
+	return func(o *backtraceOptions) { o.maxBuffers = n }
+}
+
+// BacktraceHandler is a "fingers crossed" handler: records, that are not
+// enabled by the wrapped handler, are kept in a ring buffer instead of being
+// dropped, and are passed to the wrapped handler, once the record at or above
+// the trigger level arrives, right before it.
+//
+// Buffers are kept per key, e.g. per request, and must be dropped with
+// Discard, when they are no longer needed, e.g. at the end of the request.
+type BacktraceHandler struct {
+	h slog.Handler
+	*backtrace
+}
+
+type backtrace struct {
+	opts backtraceOptions
+
+	mu      sync.Mutex
+	lru     *list.List // of *backtraceBuffer, most recently used first
+	buffers map[string]*list.Element
+}
+
+type backtraceBuffer struct {
+	key     string
+	entries []backtraceEntry // oldest first, grows as records are added
+	bytes   int              // approximate size of the records
+}
+
+type backtraceEntry struct {
+	ctx  context.Context
+	h    slog.Handler // the derived handler, the record was logged with
+	rec  slog.Record
+	size int
+}
+
+// Backtrace wraps the handler with BacktraceHandler.
+func Backtrace(h slog.Handler, opts ...BacktraceOption) *BacktraceHandler {
+	o := backtraceOptions{
+		keyFn:      func(context.Context) (string, bool) { return "", false },
+		trigger:    slog.LevelError,
+		lvl:        slog.LevelDebug,
+		size:       100,
+		maxBuffers: 1024,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &BacktraceHandler{h: h, backtrace: &backtrace{
+		opts:    o,
+		lru:     list.New(),
+		buffers: map[string]*list.Element{},
+	}}
+}
+
+// Enabled returns true if the level is enabled by the wrapped handler
+// or if the records of this level are kept in the buffer.
+func (b *BacktraceHandler) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return lvl >= b.opts.lvl.Level() || b.h.Enabled(ctx, lvl)
+}
+
+// Handle flushes the buffer, if the record is at or above the trigger level,
+// and passes the record to the wrapped handler, if it is enabled, otherwise
+// the record is kept in the buffer.
+func (b *BacktraceHandler) Handle(ctx context.Context, rec slog.Record) error {
+	key, _ := b.opts.keyFn(ctx)
+
+	var errs []error
+	if rec.Level >= b.opts.trigger.Level() {
+		for _, e := range b.take(key) {
+			if err := e.h.Handle(e.ctx, e.rec); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	switch {
+	case b.h.Enabled(ctx, rec.Level):
+		if err := b.h.Handle(ctx, rec); err != nil {
+			errs = append(errs, err)
+		}
+	case rec.Level < b.opts.trigger.Level() && rec.Level >= b.opts.lvl.Level():
+		b.put(key, backtraceEntry{ctx: context.WithoutCancel(ctx), h: b.h, rec: rec.Clone(), size: recordSize(rec)})
+	}
+
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new BacktraceHandler with the given attributes,
+// that shares the buffers with the parent one.
+func (b *BacktraceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &BacktraceHandler{h: b.h.WithAttrs(attrs), backtrace: b.backtrace}
+}
+
+// WithGroup returns a new BacktraceHandler with the given group,
+// that shares the buffers with the parent one.
+func (b *BacktraceHandler) WithGroup(name string) slog.Handler {
+	return &BacktraceHandler{h: b.h.WithGroup(name), backtrace: b.backtrace}
+}
+
+// Discard drops the buffer for the key from the context.
+func (b *BacktraceHandler) Discard(ctx context.Context) {
+	key, _ := b.opts.keyFn(ctx)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if el, ok := b.buffers[key]; ok {
+		b.lru.Remove(el)
+		delete(b.buffers, key)
+	}
+}
+
+func (bt *backtrace) put(key string, e backtraceEntry) {
+	if bt.opts.size <= 0 {
+		return
+	}
+
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	var buf *backtraceBuffer
+	if el, ok := bt.buffers[key]; ok {
+		bt.lru.MoveToFront(el)
+		buf = el.Value.(*backtraceBuffer)
+	} else {
+		buf = &backtraceBuffer{key: key}
+		bt.buffers[key] = bt.lru.PushFront(buf)
+		for bt.lru.Len() > bt.opts.maxBuffers {
+			delete(bt.buffers, bt.lru.Remove(bt.lru.Back()).(*backtraceBuffer).key)
+		}
+	}
+
+	buf.entries = append(buf.entries, e)
+	buf.bytes += e.size
+
+	// drop the oldest entries
+	for len(buf.entries) > bt.opts.size || bt.opts.maxBytes > 0 && buf.bytes > bt.opts.maxBytes {
+		buf.bytes -= buf.entries[0].size
+		buf.entries[0] = backtraceEntry{}
+		buf.entries = buf.entries[1:]
+	}
+}
+
+// take removes the buffer for the key and returns its entries in
+// the order they were added.
+func (bt *backtrace) take(key string) []backtraceEntry {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+
+	el, ok := bt.buffers[key]
+	if !ok {
+		return nil
+	}
+	bt.lru.Remove(el)
+	delete(bt.buffers, key)
+
+	return el.Value.(*backtraceBuffer).entries
+}
+
+// recordSize returns the approximate size of the record, see BacktraceMaxBytes.
+func recordSize(rec slog.Record) int {
+	n := len(rec.Message)
+	rec.Attrs(func(a slog.Attr) bool {
+		n += attrSize(a)
+		return true
+	})
+	return n
+}
+
+func attrSize(a slog.Attr) int {
+	n := len(a.Key)
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return n + len(a.Value.String())
+	case slog.KindGroup:
+		for _, ga := range a.Value.Group() {
+			n += attrSize(ga)
+		}
+		return n
+	default:
+		return n + 8
+	}
+}
@@ -0,0 +1,161 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type backtraceKey struct{}
+
+func backtraceKeyFromContext(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(backtraceKey{}).(string)
+	return v, ok
+}
+
+func decodeMessages(t *testing.T, buf *bytes.Buffer) []string {
+	t.Helper()
+
+	var res []string
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var entry map[string]any
+		require.NoError(t, dec.Decode(&entry))
+		res = append(res, entry["msg"].(string))
+	}
+	return res
+}
+
+func TestBacktrace(t *testing.T) {
+	t.Run("flushes on trigger", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		lg := slog.New(Backtrace(slog.NewJSONHandler(buf, nil)))
+
+		lg.Debug("debug 1")
+		lg.Info("info")
+		lg.Debug("debug 2")
+		assert.Equal(t, []string{"info"}, decodeMessages(t, buf))
+
+		lg.Error("error")
+		assert.Equal(t, []string{"debug 1", "debug 2", "error"}, decodeMessages(t, buf))
+
+		// buffer is emptied after the flush
+		lg.Error("error")
+		assert.Equal(t, []string{"error"}, decodeMessages(t, buf))
+	})
+
+	t.Run("per key", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := Backtrace(slog.NewJSONHandler(buf, nil), BacktraceKey(backtraceKeyFromContext))
+		lg := slog.New(h)
+
+		ctx1 := context.WithValue(context.Background(), backtraceKey{}, "req1")
+		ctx2 := context.WithValue(context.Background(), backtraceKey{}, "req2")
+
+		lg.DebugContext(ctx1, "req1 debug")
+		lg.DebugContext(ctx2, "req2 debug")
+		lg.Debug("global debug")
+
+		lg.ErrorContext(ctx2, "req2 error")
+		assert.Equal(t, []string{"req2 debug", "req2 error"}, decodeMessages(t, buf))
+
+		lg.Error("global error")
+		assert.Equal(t, []string{"global debug", "global error"}, decodeMessages(t, buf))
+
+		h.Discard(ctx1)
+		lg.ErrorContext(ctx1, "req1 error")
+		assert.Equal(t, []string{"req1 error"}, decodeMessages(t, buf))
+	})
+
+	t.Run("size", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		lg := slog.New(Backtrace(slog.NewJSONHandler(buf, nil), BacktraceSize(3)))
+		for i := range 5 {
+			lg.Debug("debug " + strconv.Itoa(i))
+		}
+		lg.Error("error")
+		assert.Equal(t, []string{"debug 2", "debug 3", "debug 4", "error"}, decodeMessages(t, buf))
+	})
+
+	t.Run("grows lazily", func(t *testing.T) {
+		h := Backtrace(slog.NewJSONHandler(&bytes.Buffer{}, nil), BacktraceSize(1000))
+		slog.New(h).Debug("debug")
+		require.Len(t, h.buffers, 1)
+		assert.Less(t, cap(h.buffers[""].Value.(*backtraceBuffer).entries), 10)
+	})
+
+	t.Run("max bytes", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		lg := slog.New(Backtrace(slog.NewJSONHandler(buf, nil), BacktraceMaxBytes(30)))
+		lg.Debug("debug 0", slog.String("k", "long value"))            // 7+1+10 = 18 bytes
+		lg.Debug("debug 1", slog.Int("n", 1))                          // 7+1+8 = 16 bytes
+		lg.Debug("debug 2", slog.Group("g", slog.String("k", "v")))    // 7+1+1+1 = 10 bytes
+		lg.Debug("debug 3", slog.String("k", strings.Repeat("v", 40))) // larger than the limit
+		lg.Debug("debug 4")
+		lg.Error("error")
+		assert.Equal(t, []string{"debug 4", "error"}, decodeMessages(t, buf))
+
+		lg.Debug("debug 0", slog.String("k", "long value"))
+		lg.Debug("debug 1", slog.Int("n", 1))
+		lg.Debug("debug 2", slog.Group("g", slog.String("k", "v")))
+		lg.Error("error")
+		assert.Equal(t, []string{"debug 1", "debug 2", "error"}, decodeMessages(t, buf))
+	})
+
+	t.Run("max buffers", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		lg := slog.New(Backtrace(slog.NewJSONHandler(buf, nil),
+			BacktraceKey(backtraceKeyFromContext), BacktraceMaxBuffers(2)))
+
+		ctxs := make([]context.Context, 3)
+		for i := range ctxs {
+			ctxs[i] = context.WithValue(context.Background(), backtraceKey{}, strconv.Itoa(i))
+			lg.DebugContext(ctxs[i], "debug "+strconv.Itoa(i))
+		}
+
+		for i := range ctxs {
+			lg.ErrorContext(ctxs[i], "error "+strconv.Itoa(i))
+		}
+		assert.Equal(t, []string{"error 0", "debug 1", "error 1", "debug 2", "error 2"}, decodeMessages(t, buf))
+	})
+
+	t.Run("trigger and level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := Backtrace(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelWarn}),
+			BacktraceTrigger(slog.LevelWarn), BacktraceLevel(slog.LevelInfo))
+		assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+
+		lg := slog.New(h)
+		lg.Info("info")
+		lg.Warn("warn")
+		assert.Equal(t, []string{"info", "warn"}, decodeMessages(t, buf))
+
+		// records below the level are not kept, even if Enabled is skipped
+		require.NoError(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelDebug, "debug", 0)))
+		lg.Warn("warn")
+		assert.Equal(t, []string{"warn"}, decodeMessages(t, buf))
+	})
+
+	t.Run("derived handlers", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		lg := slog.New(Backtrace(slog.NewJSONHandler(buf, nil)))
+
+		lg.With(slog.String("a", "b")).WithGroup("g").Debug("debug", slog.Int("x", 1))
+		lg.Error("error")
+
+		var entry map[string]any
+		require.NoError(t, json.NewDecoder(buf).Decode(&entry))
+		assert.Equal(t, "debug", entry["msg"])
+		assert.Equal(t, "b", entry["a"])
+		assert.Equal(t, map[string]any{"x": float64(1)}, entry["g"])
+	})
+}
@@ -6,38 +6,116 @@ import (
 )
 
 // Chain is a chain of middleware.
+// Middlewares are composed once, when the chain is built and when it is
+// derived with WithAttrs and WithGroup, instead of on every record, so the
+// state, that middlewares keep between the constructor and the returned
+// HandleFunc, is shared between records and must be safe for concurrent use.
 type Chain struct {
-	mws []Middleware
+	mws []Interceptor
+	lvl slog.Leveler
 	slog.Handler
+
+	// composed functions
+	handle  HandleFunc
+	enabled EnabledFunc
 }
 
 // NewChain returns a new Chain with the given middleware.
 func NewChain(base slog.Handler, mws ...Middleware) *Chain {
-	return &Chain{mws: mws, Handler: base}
+	is := make([]Interceptor, len(mws))
+	for i, mw := range mws {
+		is[i] = mw
+	}
+	return newChain(base, is, nil)
+}
+
+func newChain(base slog.Handler, mws []Interceptor, lvl slog.Leveler) *Chain {
+	c := &Chain{mws: mws, lvl: lvl, Handler: base}
+
+	c.handle = base.Handle
+	c.enabled = base.Enabled
+	for i := len(mws) - 1; i >= 0; i-- {
+		c.handle = mws[i].Wrap(c.handle)
+		if ei, ok := mws[i].(EnabledInterceptor); ok {
+			c.enabled = ei.WrapEnabled(c.enabled)
+		}
+	}
+
+	return c
+}
+
+// Use returns a new Chain with the given interceptors appended
+// to the end of the chain.
+func (c *Chain) Use(is ...Interceptor) *Chain {
+	mws := make([]Interceptor, 0, len(c.mws)+len(is))
+	mws = append(mws, c.mws...)
+	mws = append(mws, is...)
+	return newChain(c.Handler, mws, c.lvl)
+}
+
+// WithLevel returns a new Chain, that is enabled only for records
+// with the level not less than lvl, in addition to the base handler's check.
+// The level may be changed at runtime, e.g. with slog.LevelVar or
+// with the one from LevelRegistry.
+func (c *Chain) WithLevel(lvl slog.Leveler) *Chain {
+	return newChain(c.Handler, c.mws, lvl)
+}
+
+// Enabled returns true if the level is enabled by the chain's level (if set),
+// the interceptors, that implement EnabledInterceptor, and the base handler.
+func (c *Chain) Enabled(ctx context.Context, lvl slog.Level) bool {
+	if c.lvl != nil && lvl < c.lvl.Level() {
+		return false
+	}
+	if c.enabled == nil { // chain is not built with NewChain
+		return c.Handler.Enabled(ctx, lvl)
+	}
+	return c.enabled(ctx, lvl)
 }
 
 // Handle runs the chain of middleware and the handler.
 func (c *Chain) Handle(ctx context.Context, rec slog.Record) error {
-	h := c.Handler.Handle
-	for i := len(c.mws) - 1; i >= 0; i-- {
-		h = c.mws[i](h)
+	if c.handle == nil { // chain is not built with NewChain
+		return c.Handler.Handle(ctx, rec)
 	}
-	return h(ctx, rec)
+	return c.handle(ctx, rec)
 }
 
 // WithGroup returns a new Chain with the given group.
-// It applies middlewares on the top-level handler.
+// It applies middlewares on the top-level handler,
+// unless they implement GroupInterceptor.
 func (c *Chain) WithGroup(group string) slog.Handler {
-	return &Chain{
-		mws:     c.mws,
-		Handler: c.Handler.WithGroup(group),
+	mws := make([]Interceptor, len(c.mws))
+	for i, mw := range c.mws {
+		mws[i] = mw
+		if gi, ok := mw.(GroupInterceptor); ok && group != "" {
+			mws[i], group = gi.WithGroup(group)
+		}
+	}
+
+	h := c.Handler
+	if group != "" {
+		h = h.WithGroup(group)
 	}
+
+	return newChain(h, mws, c.lvl)
 }
 
-// WithAttrs returns a new Chain with the given attributes.
+// WithAttrs returns a new Chain with the given attributes,
+// passed through the middlewares, that implement AttrsInterceptor.
 func (c *Chain) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &Chain{
-		mws:     c.mws,
-		Handler: c.Handler.WithAttrs(attrs),
+	mws := make([]Interceptor, len(c.mws))
+	for i, mw := range c.mws {
+		mws[i] = mw
+		if ai, ok := mw.(AttrsInterceptor); ok && len(attrs) > 0 {
+			mws[i], attrs = ai.WithAttrs(attrs)
+		}
 	}
+
+	h := c.Handler
+	if len(attrs) > 0 {
+		h = h.WithAttrs(attrs)
+	}
+
+	return newChain(h, mws, c.lvl)
 }
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -112,3 +114,162 @@ func TestChain_WithAttrs(t *testing.T) {
 	assert.Equal(t, "1", entry.A)
 	assert.Equal(t, "2", entry.B)
 }
+
+type debugCtxKey struct{}
+
+// ctxLevelInterceptor enables debug records only for contexts with debugCtxKey.
+type ctxLevelInterceptor struct{}
+
+func (ctxLevelInterceptor) Wrap(next HandleFunc) HandleFunc { return next }
+
+func (ctxLevelInterceptor) WrapEnabled(next EnabledFunc) EnabledFunc {
+	return func(ctx context.Context, lvl slog.Level) bool {
+		if lvl < slog.LevelInfo && ctx.Value(debugCtxKey{}) == nil {
+			return false
+		}
+		return next(ctx, lvl)
+	}
+}
+
+// holdingInterceptor keeps the attributes and groups to itself
+// and adds them to the records, wrapping them with "held" prefix.
+type holdingInterceptor struct{ held []slog.Attr }
+
+func (h holdingInterceptor) Wrap(next HandleFunc) HandleFunc {
+	return func(ctx context.Context, rec slog.Record) error {
+		for _, a := range h.held {
+			rec.AddAttrs(slog.String("held_"+a.Key, a.Value.String()))
+		}
+		return next(ctx, rec)
+	}
+}
+
+func (h holdingInterceptor) WithAttrs(attrs []slog.Attr) (Interceptor, []slog.Attr) {
+	return holdingInterceptor{held: append(h.held[:len(h.held):len(h.held)], attrs...)}, nil
+}
+
+func (h holdingInterceptor) WithGroup(name string) (Interceptor, string) {
+	return holdingInterceptor{held: append(h.held[:len(h.held):len(h.held)], slog.String("group", name))}, ""
+}
+
+func TestChain_Use(t *testing.T) {
+	t.Run("enabled interceptor", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		called := 0
+		h := NewChain(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})).
+			Use(ctxLevelInterceptor{}, Middleware(func(next HandleFunc) HandleFunc {
+				return func(ctx context.Context, rec slog.Record) error {
+					called++
+					return next(ctx, rec)
+				}
+			}))
+
+		lg := slog.New(h)
+		lg.Debug("skipped")
+		lg.Info("passed")
+		lg.DebugContext(context.WithValue(context.Background(), debugCtxKey{}, true), "debug passed")
+
+		assert.NotContains(t, buf.String(), "skipped")
+		assert.Contains(t, buf.String(), "passed")
+		assert.Contains(t, buf.String(), "debug passed")
+		assert.Equal(t, 2, called)
+	})
+
+	t.Run("attrs and group interceptor", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		h := NewChain(slog.NewJSONHandler(buf, nil)).Use(holdingInterceptor{})
+
+		slog.New(h).With(slog.String("a", "1")).WithGroup("g").Info("test", slog.String("b", "2"))
+
+		t.Log(buf.String())
+
+		var entry map[string]any
+		require.NoError(t, json.NewDecoder(buf).Decode(&entry))
+		assert.Equal(t, "1", entry["held_a"])
+		assert.Equal(t, "g", entry["held_group"])
+		assert.Equal(t, "2", entry["b"], "group must not be passed to the base handler")
+		assert.NotContains(t, entry, "a")
+	})
+}
+
+func TestChain_ComposedOnce(t *testing.T) {
+	composed := 0
+	mw := func(next HandleFunc) HandleFunc {
+		composed++
+		handled := 0 // per-composition state
+		return func(ctx context.Context, rec slog.Record) error {
+			handled++
+			rec.AddAttrs(slog.Int("handled", handled))
+			return next(ctx, rec)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	h := NewChain(slog.NewJSONHandler(buf, nil), mw)
+	lg := slog.New(h)
+	lg.Info("first")
+	lg.Info("second")
+	assert.Equal(t, 1, composed)
+
+	child := lg.With(slog.String("a", "1"))
+	assert.Equal(t, 2, composed)
+	child.Info("third")
+	lg.Info("fourth")
+
+	var handled []float64
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var entry map[string]any
+		require.NoError(t, dec.Decode(&entry))
+		handled = append(handled, entry["handled"].(float64))
+	}
+	assert.Equal(t, []float64{1, 2, 1, 3}, handled)
+}
+
+// legacyChain is the previous implementation of the Chain,
+// which composes the middlewares on every record.
+type legacyChain struct {
+	mws []Middleware
+	slog.Handler
+}
+
+func (c *legacyChain) Handle(ctx context.Context, rec slog.Record) error {
+	h := c.Handler.Handle
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		h = c.mws[i](h)
+	}
+	return h(ctx, rec)
+}
+
+func BenchmarkChain(b *testing.B) {
+	passThrough := func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error { return next(ctx, rec) }
+	}
+
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+	rec.AddAttrs(slog.Int("int", 1))
+
+	for _, n := range []int{0, 3, 10} {
+		mws := make([]Middleware, n)
+		for i := range mws {
+			mws[i] = passThrough
+		}
+
+		for _, bb := range []struct {
+			name string
+			h    slog.Handler
+		}{
+			{name: "precomposed", h: NewChain(NopHandler(), mws...)},
+			{name: "legacy", h: &legacyChain{mws: mws, Handler: NopHandler()}},
+		} {
+			b.Run(fmt.Sprintf("%s/%d", bb.name, n), func(b *testing.B) {
+				b.ReportAllocs()
+				ctx := context.Background()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_ = bb.h.Handle(ctx, rec)
+				}
+			})
+		}
+	}
+}
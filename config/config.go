@@ -0,0 +1,179 @@
+// Package config builds slog handlers from the declarative description
+// of the pipeline: the base handler and the ordered list of middlewares,
+// written in JSON or YAML, e.g.:
+//
+//	handler:
+//	  type: json
+//	  level: debug
+//	  output: stderr
+//	middlewares:
+//	  - type: request_id
+//	  - type: trim_attrs
+//	    limit: 1024
+//
+// Parameters of the handler and middlewares are written next to their type,
+// the set of types may be extended with RegisterHandler and RegisterMiddleware.
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/cappuccinotm/slogx"
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes the pipeline of the handler.
+type Config struct {
+	Handler     HandlerConfig      `yaml:"handler"`
+	Middlewares []MiddlewareConfig `yaml:"middlewares"`
+}
+
+// HandlerConfig describes the base handler.
+type HandlerConfig struct {
+	Type  string
+	Level slog.Level
+	// Params contains the parameters of the handler, including the type and level.
+	Params Params
+}
+
+// MiddlewareConfig describes the middleware.
+type MiddlewareConfig struct {
+	Type string
+	// Params contains the parameters of the middleware, including the type.
+	Params Params
+}
+
+// Params decodes the parameters of the handler or middleware
+// into the given value, e.g. a struct with yaml tags.
+type Params interface {
+	Decode(v any) error
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *HandlerConfig) UnmarshalYAML(node *yaml.Node) error {
+	var v struct {
+		Type  string `yaml:"type"`
+		Level string `yaml:"level"`
+	}
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+
+	c.Type, c.Level, c.Params = v.Type, slog.LevelInfo, node
+	if v.Level == "" {
+		return nil
+	}
+
+	lvl, err := slogx.ParseLevel(v.Level)
+	if err != nil {
+		return fmt.Errorf("parse level: %w", err)
+	}
+
+	c.Level = lvl
+
+	return nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (c *MiddlewareConfig) UnmarshalYAML(node *yaml.Node) error {
+	var v struct {
+		Type string `yaml:"type"`
+	}
+	if err := node.Decode(&v); err != nil {
+		return err
+	}
+
+	c.Type, c.Params = v.Type, node
+	return nil
+}
+
+// Parse parses the config from JSON or YAML.
+func Parse(data []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+// Build builds the handler from the config with the default registry.
+// If the base handler holds any resources, e.g. the one of "file" type,
+// the built handler implements io.Closer and must be closed, when it is
+// no longer needed.
+func Build(cfg Config) (slog.Handler, error) {
+	return defaultRegistry.Build(cfg)
+}
+
+// Load reads the config from the file and builds the handler with the
+// default registry.
+func Load(path string) (slog.Handler, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Build(cfg)
+}
+
+// Build builds the handler from the config.
+func (r *Registry) Build(cfg Config) (slog.Handler, error) {
+	hf, ok := r.handler(cfg.Handler.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown handler type %q", cfg.Handler.Type)
+	}
+
+	base, err := hf(paramsOrEmpty(cfg.Handler.Params), cfg.Handler.Level)
+	if err != nil {
+		return nil, fmt.Errorf("build %q handler: %w", cfg.Handler.Type, err)
+	}
+
+	mws := make([]slogx.Middleware, 0, len(cfg.Middlewares))
+	for i, mc := range cfg.Middlewares {
+		mf, ok := r.middleware(mc.Type)
+		if !ok {
+			_ = closeHandler(base)
+			return nil, fmt.Errorf("middleware #%d: unknown type %q", i, mc.Type)
+		}
+
+		mw, err := mf(paramsOrEmpty(mc.Params))
+		if err != nil {
+			_ = closeHandler(base)
+			return nil, fmt.Errorf("build middleware #%d %q: %w", i, mc.Type, err)
+		}
+
+		mws = append(mws, mw)
+	}
+
+	if len(mws) == 0 {
+		return base, nil
+	}
+
+	return &pipeline{Chain: slogx.NewChain(base, mws...), base: base}, nil
+}
+
+// pipeline is a chain, that may be closed, if its base handler is closable.
+type pipeline struct {
+	*slogx.Chain
+	base slog.Handler
+}
+
+// Close closes the base handler, if it implements io.Closer.
+func (p *pipeline) Close() error { return closeHandler(p.base) }
+
+func paramsOrEmpty(p Params) Params {
+	if p == nil {
+		return emptyParams{}
+	}
+	return p
+}
+
+type emptyParams struct{}
+
+func (emptyParams) Decode(any) error { return nil }
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/slogm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		cfg, err := Parse([]byte(`
+handler:
+  type: json
+  level: debug
+  output: stderr
+middlewares:
+  - type: request_id
+  - type: trim_attrs
+    limit: 10
+`))
+		require.NoError(t, err)
+		assert.Equal(t, "json", cfg.Handler.Type)
+		assert.Equal(t, slog.LevelDebug, cfg.Handler.Level)
+		require.Len(t, cfg.Middlewares, 2)
+		assert.Equal(t, "request_id", cfg.Middlewares[0].Type)
+		assert.Equal(t, "trim_attrs", cfg.Middlewares[1].Type)
+
+		var p struct {
+			Limit int `yaml:"limit"`
+		}
+		require.NoError(t, cfg.Middlewares[1].Params.Decode(&p))
+		assert.Equal(t, 10, p.Limit)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		cfg, err := Parse([]byte(`{"handler": {"type": "text", "level": "WARN+2"}, "middlewares": [{"type": "mask_secrets"}]}`))
+		require.NoError(t, err)
+		assert.Equal(t, "text", cfg.Handler.Type)
+		assert.Equal(t, slog.LevelWarn+2, cfg.Handler.Level)
+		require.Len(t, cfg.Middlewares, 1)
+		assert.Equal(t, "mask_secrets", cfg.Middlewares[0].Type)
+	})
+
+	t.Run("default level", func(t *testing.T) {
+		cfg, err := Parse([]byte(`handler: {type: json}`))
+		require.NoError(t, err)
+		assert.Equal(t, slog.LevelInfo, cfg.Handler.Level)
+	})
+
+	t.Run("custom level", func(t *testing.T) {
+		cfg, err := Parse([]byte(`handler: {type: json, level: trace}`))
+		require.NoError(t, err)
+		assert.Equal(t, slogx.LevelTrace, cfg.Handler.Level)
+	})
+
+	t.Run("invalid level", func(t *testing.T) {
+		_, err := Parse([]byte(`handler: {type: json, level: loud}`))
+		assert.ErrorContains(t, err, "parse level")
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		_, err := Parse([]byte(`handler: [`))
+		assert.Error(t, err)
+	})
+}
+
+// bufferRegistry returns a registry with the "buffer" handler type,
+// that writes JSON to the buffer.
+func bufferRegistry(buf *bytes.Buffer) *Registry {
+	r := NewRegistry()
+	r.RegisterHandler("buffer", func(_ Params, lvl slog.Level) (slog.Handler, error) {
+		return slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: lvl}), nil
+	})
+	return r
+}
+
+func TestRegistry_Build(t *testing.T) {
+	t.Run("middlewares in order", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		r := bufferRegistry(buf)
+		r.RegisterMiddleware("add", func(params Params) (slogx.Middleware, error) {
+			var p struct {
+				Key   string `yaml:"key"`
+				Value string `yaml:"value"`
+			}
+			if err := params.Decode(&p); err != nil {
+				return nil, err
+			}
+			return func(next slogx.HandleFunc) slogx.HandleFunc {
+				return func(ctx context.Context, rec slog.Record) error {
+					rec.AddAttrs(slog.String(p.Key, p.Value))
+					return next(ctx, rec)
+				}
+			}, nil
+		})
+
+		cfg, err := Parse([]byte(`
+handler: {type: buffer, level: debug}
+middlewares:
+  - {type: request_id}
+  - {type: add, key: secret, value: "some secret value"}
+  - {type: mask_secrets, replacement: "<hidden>"}
+  - {type: trim_attrs, limit: 10}
+`))
+		require.NoError(t, err)
+
+		h, err := r.Build(cfg)
+		require.NoError(t, err)
+
+		ctx := slogm.ContextWithRequestID(context.Background(), "req-1")
+		ctx = slogm.AddSecrets(ctx, "secret")
+		slog.New(h).DebugContext(ctx, "message")
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, "DEBUG", entry["level"])
+		assert.Equal(t, "req-1", entry["request_id"])
+		assert.Equal(t, "some <hidd...", entry["secret"])
+	})
+
+	t.Run("level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		cfg, err := Parse([]byte(`handler: {type: buffer, level: warn}`))
+		require.NoError(t, err)
+
+		h, err := bufferRegistry(buf).Build(cfg)
+		require.NoError(t, err)
+		assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+		assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+	})
+
+	t.Run("built-in handlers", func(t *testing.T) {
+		for _, doc := range []string{
+			`handler: {type: json, output: stdout, add_source: true}`,
+			`handler: {type: text, output: stderr}`,
+			`handler: {type: fblog, output: stderr, source: pos}`,
+			`handler: {type: fblog}`,
+		} {
+			cfg, err := Parse([]byte(doc))
+			require.NoError(t, err)
+			_, err = Build(cfg)
+			assert.NoError(t, err, doc)
+		}
+	})
+
+	t.Run("built-in middlewares", func(t *testing.T) {
+		cfg, err := Parse([]byte(`
+handler: {type: json}
+middlewares:
+  - {type: request_id}
+  - {type: stacktrace_on_error}
+  - {type: context_attrs}
+  - {type: error_attrs}
+  - {type: trim_attrs, limit: 1024}
+  - {type: mask_secrets}
+  - {type: sample, first: 10, thereafter: 100, tick: 1s}
+  - {type: rate_limit, limit: 10, burst: 20}
+`))
+		require.NoError(t, err)
+		_, err = Build(cfg)
+		assert.NoError(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for doc, errText := range map[string]string{
+			`handler: {type: unknown}`:                                             `unknown handler type "unknown"`,
+			`handler: {type: json, output: /tmp/file}`:                             `unknown output`,
+			`handler: {type: fblog, source: everywhere}`:                           `unknown source format`,
+			`handler: {type: file}`:                                                `path is required`,
+			`{handler: {type: json}, middlewares: [{type: unknown}]}`:              `middleware #0: unknown type "unknown"`,
+			`{handler: {type: json}, middlewares: [{type: trim_attrs}]}`:           `limit must be positive`,
+			`{handler: {type: json}, middlewares: [{type: trim_attrs, limit: x}]}`: `build middleware #0 "trim_attrs"`,
+			`{handler: {type: json}, middlewares: [{type: rate_limit, limit: 1}]}`: `limit and burst must be positive`,
+		} {
+			cfg, err := Parse([]byte(doc))
+			require.NoError(t, err)
+			_, err = Build(cfg)
+			assert.ErrorContains(t, err, errText, doc)
+		}
+	})
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	cfgPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte(`
+handler:
+  type: file
+  path: `+logPath+`
+  format: text
+middlewares:
+  - type: request_id
+`), 0o600))
+
+	h, err := Load(cfgPath)
+	require.NoError(t, err)
+
+	ctx := slogm.ContextWithRequestID(context.Background(), "req-1")
+	slog.New(h).InfoContext(ctx, "message")
+	require.NoError(t, h.(io.Closer).Close())
+
+	data, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `msg=message request_id=req-1`)
+
+	_, err = Load(filepath.Join(dir, "missing.yaml"))
+	assert.ErrorContains(t, err, "read config")
+}
+
+func TestBuild_FileRotation(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := Parse([]byte(`
+handler:
+  type: file
+  path: ` + filepath.Join(dir, "app.log") + `
+  max_size: 10
+  max_backups: 1
+  compress: true
+`))
+	require.NoError(t, err)
+
+	h, err := Build(cfg)
+	require.NoError(t, err)
+
+	lg := slog.New(h)
+	for range 3 {
+		lg.Info("message")
+	}
+	require.NoError(t, h.(io.Closer).Close())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "app.log", entries[1].Name())
+	assert.Regexp(t, `^app-.*\.log\.gz$`, entries[0].Name())
+}
+
+func TestBuild_LevelNames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg, err := Parse([]byte(`handler: {type: file, path: ` + path + `, level: trace}`))
+	require.NoError(t, err)
+
+	h, err := Build(cfg)
+	require.NoError(t, err)
+
+	slog.New(h).Log(context.Background(), slogx.LevelTrace, "trace")
+	require.NoError(t, h.(io.Closer).Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"level":"TRACE"`)
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/fblog"
+	"github.com/cappuccinotm/slogx/file"
+	"github.com/cappuccinotm/slogx/slogm"
+)
+
+// HandlerFactory builds the base handler of the given level from the parameters.
+// If the handler holds any resources, it should implement io.Closer.
+type HandlerFactory func(params Params, lvl slog.Level) (slog.Handler, error)
+
+// MiddlewareFactory builds the middleware from the parameters.
+type MiddlewareFactory func(params Params) (slogx.Middleware, error)
+
+// Registry is a set of the handler and middleware types, available in configs.
+type Registry struct {
+	mu          sync.RWMutex
+	handlers    map[string]HandlerFactory
+	middlewares map[string]MiddlewareFactory
+}
+
+var defaultRegistry = NewRegistry()
+
+// NewRegistry makes a new Registry with the built-in types:
+//   - handlers: "json", "text", "fblog" and "file";
+//   - middlewares: "request_id", "stacktrace_on_error", "trim_attrs",
+//     "mask_secrets", "context_attrs", "error_attrs", "sample" and "rate_limit".
+func NewRegistry() *Registry {
+	r := &Registry{handlers: map[string]HandlerFactory{}, middlewares: map[string]MiddlewareFactory{}}
+
+	r.RegisterHandler("json", func(params Params, lvl slog.Level) (slog.Handler, error) {
+		return newStdHandler(params, lvl, func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewJSONHandler(w, opts)
+		})
+	})
+	r.RegisterHandler("text", func(params Params, lvl slog.Level) (slog.Handler, error) {
+		return newStdHandler(params, lvl, func(w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+			return slog.NewTextHandler(w, opts)
+		})
+	})
+	r.RegisterHandler("fblog", newFblogHandler)
+	r.RegisterHandler("file", newFileHandler)
+
+	r.RegisterMiddleware("request_id", noParams(slogm.RequestID))
+	r.RegisterMiddleware("stacktrace_on_error", noParams(slogm.StacktraceOnError))
+	r.RegisterMiddleware("context_attrs", noParams(slogm.ContextAttrs))
+	r.RegisterMiddleware("error_attrs", noParams(slogm.ErrorAttrs))
+	r.RegisterMiddleware("trim_attrs", func(params Params) (slogx.Middleware, error) {
+		var p struct {
+			Limit int `yaml:"limit"`
+		}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		if p.Limit <= 0 {
+			return nil, errors.New("limit must be positive")
+		}
+		return slogm.TrimAttrs(p.Limit), nil
+	})
+	r.RegisterMiddleware("mask_secrets", func(params Params) (slogx.Middleware, error) {
+		p := struct {
+			Replacement string `yaml:"replacement"`
+		}{Replacement: "***"}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		return slogm.MaskSecrets(p.Replacement), nil
+	})
+	r.RegisterMiddleware("sample", func(params Params) (slogx.Middleware, error) {
+		var p struct {
+			First      uint64        `yaml:"first"`
+			Thereafter uint64        `yaml:"thereafter"`
+			Tick       time.Duration `yaml:"tick"`
+		}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		var opts []slogm.SampleOption
+		if p.Tick > 0 {
+			opts = append(opts, slogm.SampleTick(p.Tick))
+		}
+		return slogm.Sample(p.First, p.Thereafter, opts...), nil
+	})
+	r.RegisterMiddleware("rate_limit", func(params Params) (slogx.Middleware, error) {
+		var p struct {
+			Limit float64 `yaml:"limit"`
+			Burst int     `yaml:"burst"`
+		}
+		if err := params.Decode(&p); err != nil {
+			return nil, err
+		}
+		if p.Limit <= 0 || p.Burst <= 0 {
+			return nil, errors.New("limit and burst must be positive")
+		}
+		return slogm.RateLimit(p.Limit, p.Burst), nil
+	})
+
+	return r
+}
+
+// RegisterHandler adds the handler type to the default registry.
+func RegisterHandler(name string, f HandlerFactory) { defaultRegistry.RegisterHandler(name, f) }
+
+// RegisterMiddleware adds the middleware type to the default registry.
+func RegisterMiddleware(name string, f MiddlewareFactory) {
+	defaultRegistry.RegisterMiddleware(name, f)
+}
+
+// RegisterHandler adds the handler type to the registry,
+// replacing the existing one with the same name.
+func (r *Registry) RegisterHandler(name string, f HandlerFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[name] = f
+}
+
+// RegisterMiddleware adds the middleware type to the registry,
+// replacing the existing one with the same name.
+func (r *Registry) RegisterMiddleware(name string, f MiddlewareFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares[name] = f
+}
+
+func (r *Registry) handler(name string) (HandlerFactory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.handlers[name]
+	return f, ok
+}
+
+func (r *Registry) middleware(name string) (MiddlewareFactory, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	f, ok := r.middlewares[name]
+	return f, ok
+}
+
+func noParams(fn func() slogx.Middleware) MiddlewareFactory {
+	return func(Params) (slogx.Middleware, error) { return fn(), nil }
+}
+
+func output(name string) (io.Writer, error) {
+	switch name {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	default:
+		return nil, fmt.Errorf("unknown output %q, use \"file\" handler to write to files", name)
+	}
+}
+
+func newStdHandler(params Params, lvl slog.Level,
+	fn func(io.Writer, *slog.HandlerOptions) slog.Handler,
+) (slog.Handler, error) {
+	var p struct {
+		Output    string `yaml:"output"`
+		AddSource bool   `yaml:"add_source"`
+	}
+	if err := params.Decode(&p); err != nil {
+		return nil, err
+	}
+
+	w, err := output(p.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	return fn(w, &slog.HandlerOptions{Level: lvl, AddSource: p.AddSource, ReplaceAttr: slogx.ReplaceLevelName}), nil
+}
+
+func newFblogHandler(params Params, lvl slog.Level) (slog.Handler, error) {
+	var p struct {
+		Output string `yaml:"output"`
+		Source string `yaml:"source"`
+	}
+	if err := params.Decode(&p); err != nil {
+		return nil, err
+	}
+
+	opts := []fblog.Option{fblog.WithLevel(lvl)}
+
+	if p.Output != "" {
+		w, err := output(p.Output)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, fblog.Out(w), fblog.Err(w))
+	}
+
+	switch p.Source {
+	case "", "none":
+	case "pos":
+		opts = append(opts, fblog.WithSource(fblog.SourceFormatPos))
+	case "func":
+		opts = append(opts, fblog.WithSource(fblog.SourceFormatFunc))
+	case "long":
+		opts = append(opts, fblog.WithSource(fblog.SourceFormatLong))
+	default:
+		return nil, fmt.Errorf("unknown source format %q", p.Source)
+	}
+
+	return fblog.NewHandler(opts...), nil
+}
+
+// fileHandler is a handler, that writes to the file and closes it on Close.
+type fileHandler struct {
+	slog.Handler
+	w *file.Writer
+}
+
+// Close closes the file.
+func (h *fileHandler) Close() error { return h.w.Close() }
+
+func newFileHandler(params Params, lvl slog.Level) (slog.Handler, error) {
+	var p struct {
+		Path        string        `yaml:"path"`
+		Format      string        `yaml:"format"`
+		AddSource   bool          `yaml:"add_source"`
+		MaxSize     int64         `yaml:"max_size"`
+		RotateEvery time.Duration `yaml:"rotate_every"`
+		MaxBackups  int           `yaml:"max_backups"`
+		Compress    bool          `yaml:"compress"`
+	}
+	if err := params.Decode(&p); err != nil {
+		return nil, err
+	}
+
+	if p.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	var newHandler func(io.Writer, *slog.HandlerOptions) slog.Handler
+	switch p.Format {
+	case "", "json":
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewJSONHandler(w, opts) }
+	case "text":
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewTextHandler(w, opts) }
+	default:
+		return nil, fmt.Errorf("unknown format %q", p.Format)
+	}
+
+	fopts := []file.Option{file.MaxSize(p.MaxSize), file.RotateEvery(p.RotateEvery), file.MaxBackups(p.MaxBackups)}
+	if p.Compress {
+		fopts = append(fopts, file.Compress)
+	}
+
+	w, err := file.New(p.Path, fopts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileHandler{Handler: newHandler(w, &slog.HandlerOptions{Level: lvl, AddSource: p.AddSource, ReplaceAttr: slogx.ReplaceLevelName}), w: w}, nil
+}
+
+func closeHandler(h slog.Handler) error {
+	if c, ok := h.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+type watchOptions struct {
+	interval time.Duration
+	onError  func(error)
+	registry *Registry
+}
+
+// WatchOption is a functional option for Watch.
+type WatchOption func(*watchOptions)
+
+// WatchInterval sets the interval of checking the file for changes.
+// Default is 5 seconds, it is also used, if the interval is not positive.
+func WatchInterval(d time.Duration) WatchOption { return func(o *watchOptions) { o.interval = d } }
+
+// WatchOnError sets the function to be called with errors of reloading
+// the config, the previous pipeline is kept in this case.
+// By default, errors are ignored.
+func WatchOnError(fn func(error)) WatchOption { return func(o *watchOptions) { o.onError = fn } }
+
+// WatchRegistry sets the registry to build the pipeline with.
+// By default, the default registry is used.
+func WatchRegistry(r *Registry) WatchOption { return func(o *watchOptions) { o.registry = r } }
+
+// Watcher is a handler, that passes records to the pipeline, built from
+// the config file, and atomically swaps the pipeline, once the file changes.
+// Handlers, derived with WithAttrs and WithGroup, follow the swaps too.
+// The previous pipeline is closed in background, once the records, being
+// handled by it, are done.
+type Watcher struct {
+	*watcher
+	derive func(slog.Handler) slog.Handler // nil for the root handler
+	cache  atomic.Pointer[generation]      // pipeline, derived for this handler
+}
+
+type watcher struct {
+	path string
+	opts watchOptions
+
+	mu     sync.Mutex // guards reloads and closed
+	data   []byte     // content of the last loaded config
+	closed bool
+	cur    atomic.Pointer[generation]
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+	retiring  sync.WaitGroup // closing of the previous pipelines
+}
+
+type generation struct {
+	n uint64
+	h slog.Handler
+
+	refs      atomic.Int64 // records, being handled by the pipeline
+	retired   atomic.Bool  // pipeline is swapped and must not be used
+	drained   chan struct{}
+	drainOnce sync.Once
+}
+
+func newGeneration(n uint64, h slog.Handler) *generation {
+	return &generation{n: n, h: h, drained: make(chan struct{})}
+}
+
+// release marks the record as handled and notifies the retirement,
+// if it was the last one.
+func (g *generation) release() {
+	if g.refs.Add(-1) == 0 && g.retired.Load() {
+		g.drainOnce.Do(func() { close(g.drained) })
+	}
+}
+
+// retire waits for the records, being handled by the pipeline,
+// and closes it.
+func (g *generation) retire() error {
+	g.retired.Store(true)
+	if g.refs.Load() == 0 {
+		g.drainOnce.Do(func() { close(g.drained) })
+	}
+	<-g.drained
+	return closeHandler(g.h)
+}
+
+// Watch builds the pipeline from the config file and starts to check the
+// file for changes until the context is canceled or the Watcher is closed.
+func Watch(ctx context.Context, path string, opts ...WatchOption) (*Watcher, error) {
+	o := watchOptions{interval: 5 * time.Second, onError: func(error) {}, registry: defaultRegistry}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.interval <= 0 {
+		o.interval = 5 * time.Second
+	}
+
+	w := &watcher{path: path, opts: o, stop: make(chan struct{}), done: make(chan struct{})}
+	if _, err := w.reload(false); err != nil {
+		return nil, err
+	}
+
+	go w.run(ctx)
+
+	return &Watcher{watcher: w}, nil
+}
+
+// Enabled reports whether the current pipeline is enabled for the level.
+func (w *Watcher) Enabled(ctx context.Context, lvl slog.Level) bool {
+	h, g := w.acquire()
+	defer g.release()
+	return h.Enabled(ctx, lvl)
+}
+
+// Handle passes the record to the current pipeline.
+func (w *Watcher) Handle(ctx context.Context, rec slog.Record) error {
+	h, g := w.acquire()
+	defer g.release()
+	return h.Handle(ctx, rec)
+}
+
+// WithAttrs returns a new Watcher with the given attributes.
+func (w *Watcher) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return w.with(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+}
+
+// WithGroup returns a new Watcher with the given group.
+func (w *Watcher) WithGroup(name string) slog.Handler {
+	return w.with(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+}
+
+// Reload rebuilds the pipeline from the config file right away,
+// even if the file has not changed. It fails with slogx.ErrClosed
+// after Close.
+func (w *Watcher) Reload() error {
+	_, err := w.reload(true)
+	return err
+}
+
+// Close stops watching the file and closes the current pipeline, once
+// the records, being handled by it, are done. It waits for the previous
+// pipelines to be closed as well.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() { close(w.stop) })
+	<-w.done
+
+	w.mu.Lock()
+	w.closed = true
+	err := w.cur.Load().retire()
+	w.mu.Unlock()
+
+	w.retiring.Wait()
+	return err
+}
+
+func (w *Watcher) with(fn func(slog.Handler) slog.Handler) *Watcher {
+	derive := fn
+	if w.derive != nil {
+		parent := w.derive
+		derive = func(h slog.Handler) slog.Handler { return fn(parent(h)) }
+	}
+	return &Watcher{watcher: w.watcher, derive: derive}
+}
+
+// acquire returns the current pipeline, derived for this handler,
+// and its generation, which must be released once the pipeline is used.
+func (w *Watcher) acquire() (slog.Handler, *generation) {
+	for {
+		cur := w.cur.Load()
+		cur.refs.Add(1)
+		// the retired generation is still the current one only after Close
+		if !cur.retired.Load() || w.cur.Load() == cur {
+			return w.handler(cur), cur
+		}
+		cur.release()
+	}
+}
+
+// handler returns the pipeline of the generation, derived for this handler.
+func (w *Watcher) handler(cur *generation) slog.Handler {
+	if w.derive == nil {
+		return cur.h
+	}
+
+	if c := w.cache.Load(); c != nil && c.n == cur.n {
+		return c.h
+	}
+
+	c := &generation{n: cur.n, h: w.derive(cur.h)}
+	w.cache.Store(c)
+	return c.h
+}
+
+func (w *watcher) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if _, err := w.reload(false); err != nil {
+				w.opts.onError(err)
+			}
+		}
+	}
+}
+
+// reload rebuilds the pipeline, if the config file has changed or
+// if forced, and reports whether the pipeline was swapped.
+func (w *watcher) reload(force bool) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return false, slogx.ErrClosed
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, fmt.Errorf("read config: %w", err)
+	}
+
+	if !force && w.data != nil && bytes.Equal(data, w.data) {
+		return false, nil
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+
+	h, err := w.opts.registry.Build(cfg)
+	if err != nil {
+		return false, err
+	}
+
+	w.data = data
+
+	var n uint64
+	prev := w.cur.Load()
+	if prev != nil {
+		n = prev.n + 1
+	}
+
+	w.cur.Store(newGeneration(n, h))
+
+	if prev != nil {
+		w.retiring.Go(func() {
+			if cerr := prev.retire(); cerr != nil {
+				w.opts.onError(fmt.Errorf("close previous pipeline: %w", cerr))
+			}
+		})
+	}
+
+	return true, nil
+}
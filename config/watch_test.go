@@ -0,0 +1,174 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	log1, log2 := filepath.Join(dir, "1.log"), filepath.Join(dir, "2.log")
+	cfgPath := filepath.Join(dir, "config.yaml")
+
+	writeConfig := func(logPath, level string) {
+		require.NoError(t, os.WriteFile(cfgPath, []byte(
+			"handler: {type: file, format: text, level: "+level+", path: "+logPath+"}",
+		), 0o600))
+	}
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	writeConfig(log1, "info")
+	w, err := Watch(context.Background(), cfgPath, WatchInterval(10*time.Millisecond), WatchOnError(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+	require.NoError(t, err)
+
+	lg := slog.New(w)
+	derived := lg.With(slog.String("a", "b"))
+
+	lg.Debug("debug 1")
+	derived.Info("info 1")
+
+	writeConfig(log2, "debug")
+	require.Eventually(t, func() bool { return w.Enabled(context.Background(), slog.LevelDebug) },
+		time.Second, 5*time.Millisecond)
+
+	lg.Debug("debug 2")
+	derived.Info("info 2")
+
+	// invalid config keeps the previous pipeline
+	require.NoError(t, os.WriteFile(cfgPath, []byte("handler: {type: unknown}"), 0o600))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) > 0
+	}, time.Second, 5*time.Millisecond)
+
+	derived.Info("info 3")
+	require.NoError(t, w.Close())
+
+	data1, err := os.ReadFile(log1)
+	require.NoError(t, err)
+	data2, err := os.ReadFile(log2)
+	require.NoError(t, err)
+
+	assert.NotContains(t, string(data1), "debug 1")
+	assert.Contains(t, string(data1), "msg=\"info 1\" a=b")
+	assert.NotContains(t, string(data1), "info 2")
+
+	assert.Contains(t, string(data2), "msg=\"debug 2\"")
+	assert.Contains(t, string(data2), "msg=\"info 2\" a=b")
+	assert.Contains(t, string(data2), "msg=\"info 3\" a=b")
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ErrorContains(t, errs[0], `unknown handler type "unknown"`)
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("handler: {type: json, level: error}"), 0o600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, err := Watch(ctx, cfgPath, WatchInterval(time.Hour))
+	require.NoError(t, err)
+	defer w.Close()
+
+	assert.False(t, w.Enabled(ctx, slog.LevelWarn))
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte("handler: {type: json, level: warn}"), 0o600))
+	require.NoError(t, w.Reload())
+	assert.True(t, w.Enabled(ctx, slog.LevelWarn))
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte("handler: ["), 0o600))
+	assert.Error(t, w.Reload())
+	assert.True(t, w.Enabled(ctx, slog.LevelWarn))
+
+	require.NoError(t, os.WriteFile(cfgPath, []byte("handler: {type: json, level: error}"), 0o600))
+	require.NoError(t, w.Close())
+	assert.ErrorIs(t, w.Reload(), slogx.ErrClosed)
+	assert.True(t, w.Enabled(ctx, slog.LevelWarn), "closed watcher must keep the last pipeline")
+}
+
+func TestWatch_NonPositiveInterval(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("handler: {type: json}"), 0o600))
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		w, err := Watch(context.Background(), cfgPath, WatchInterval(d))
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, w.opts.interval)
+		require.NoError(t, w.Close())
+	}
+}
+
+func TestWatch_InvalidConfig(t *testing.T) {
+	_, err := Watch(context.Background(), filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorContains(t, err, "read config")
+}
+
+type blockingHandler struct {
+	slog.Handler
+	started, unblock chan struct{}
+	closed           atomic.Bool
+}
+
+func (h *blockingHandler) Handle(context.Context, slog.Record) error {
+	close(h.started)
+	<-h.unblock
+	return nil
+}
+
+func (h *blockingHandler) Close() error { h.closed.Store(true); return nil }
+
+func TestWatcher_ReloadWaitsForRecords(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(cfgPath, []byte("handler: {type: blocking}"), 0o600))
+
+	var hs []*blockingHandler
+	r := NewRegistry()
+	r.RegisterHandler("blocking", func(Params, slog.Level) (slog.Handler, error) {
+		h := &blockingHandler{Handler: slog.DiscardHandler, started: make(chan struct{}), unblock: make(chan struct{})}
+		hs = append(hs, h)
+		return h, nil
+	})
+
+	w, err := Watch(context.Background(), cfgPath, WatchInterval(time.Hour), WatchRegistry(r))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Go(func() { assert.NoError(t, w.Handle(context.Background(), slog.Record{})) })
+	<-hs[0].started
+
+	require.NoError(t, w.Reload())
+	require.Len(t, hs, 2)
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, hs[0].closed.Load(), "previous pipeline is closed while the record is being handled")
+
+	close(hs[0].unblock)
+	wg.Wait()
+	require.Eventually(t, hs[0].closed.Load, time.Second, time.Millisecond)
+
+	close(hs[1].unblock)
+	require.NoError(t, w.Close())
+	assert.True(t, hs[1].closed.Load())
+}
@@ -0,0 +1,163 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"reflect"
+)
+
+type ctxAttrsKey struct{}
+
+// ctxAttrs is a node of the list of attributes, stored in the context,
+// each child context refers to the attributes of its parent.
+type ctxAttrs struct {
+	attrs  []slog.Attr
+	raw    any  // the value of the typed field, if the node is set by ContextField
+	field  bool // whether the node is set by ContextField
+	parent *ctxAttrs
+}
+
+// ContextWithAttrs returns a new context with the given attributes added
+// to the ones already stored in the parent context.
+func ContextWithAttrs(parent context.Context, attrs ...slog.Attr) context.Context {
+	if len(attrs) == 0 {
+		return parent
+	}
+	prev, _ := parent.Value(ctxAttrsKey{}).(*ctxAttrs)
+	return context.WithValue(parent, ctxAttrsKey{}, &ctxAttrs{
+		attrs:  append([]slog.Attr(nil), attrs...), // to not share with the caller
+		parent: prev,
+	})
+}
+
+// AttrsFromContext returns all the attributes stored in the context.
+// Attributes are returned in the order their keys were added for the first
+// time, and if the same key was set more than once, the value from the
+// nearest context wins. Groups with the same key are merged in the same way.
+func AttrsFromContext(ctx context.Context) []slog.Attr {
+	last, _ := ctx.Value(ctxAttrsKey{}).(*ctxAttrs)
+	if last == nil {
+		return nil
+	}
+
+	var nodes []*ctxAttrs
+	for n := last; n != nil; n = n.parent {
+		nodes = append(nodes, n)
+	}
+
+	var res []slog.Attr
+	for i := len(nodes) - 1; i >= 0; i-- {
+		res = mergeAttrs(res, nodes[i].attrs)
+	}
+	return res
+}
+
+// mergeAttrs adds attrs to the dst, replacing the attributes with the same
+// keys and merging the groups.
+func mergeAttrs(dst, attrs []slog.Attr) []slog.Attr {
+	for _, attr := range attrs {
+		idx := -1
+		for i := range dst {
+			if dst[i].Key == attr.Key {
+				idx = i
+				break
+			}
+		}
+
+		switch {
+		case idx < 0:
+			dst = append(dst, attr)
+		case dst[idx].Value.Kind() == slog.KindGroup && attr.Value.Kind() == slog.KindGroup:
+			merged := mergeAttrs(append([]slog.Attr(nil), dst[idx].Value.Group()...), attr.Value.Group())
+			dst[idx] = slog.Attr{Key: attr.Key, Value: slog.GroupValue(merged...)}
+		default:
+			dst[idx] = attr
+		}
+	}
+	return dst
+}
+
+// ContextField is a typed definition of the attribute, stored in the context.
+type ContextField[T any] struct{ key string }
+
+// NewContextField returns a new ContextField with the given attribute key.
+// Fields with the same key refer to the same attribute.
+func NewContextField[T any](key string) ContextField[T] { return ContextField[T]{key: key} }
+
+// Key returns the attribute key of the field.
+func (f ContextField[T]) Key() string { return f.key }
+
+// With returns a new context with the field set to v.
+func (f ContextField[T]) With(parent context.Context, v T) context.Context {
+	prev, _ := parent.Value(ctxAttrsKey{}).(*ctxAttrs)
+	return context.WithValue(parent, ctxAttrsKey{}, &ctxAttrs{
+		attrs:  []slog.Attr{slog.Any(f.key, v)},
+		raw:    v,
+		field:  true,
+		parent: prev,
+	})
+}
+
+// Value returns the value of the field from the nearest context, that set it.
+// Numeric values of the plain attributes are converted to T, if they fit,
+// e.g. slog.Int("n", 1), which is stored as int64, is found by
+// NewContextField[int]("n") and NewContextField[uint8]("n").
+func (f ContextField[T]) Value(ctx context.Context) (res T, ok bool) {
+	for n, _ := ctx.Value(ctxAttrsKey{}).(*ctxAttrs); n != nil; n = n.parent {
+		for i := len(n.attrs) - 1; i >= 0; i-- {
+			if n.attrs[i].Key != f.key {
+				continue
+			}
+
+			if n.field {
+				res, ok = n.raw.(T)
+				return res, ok
+			}
+
+			return convertValue[T](n.attrs[i].Value)
+		}
+	}
+	return res, false
+}
+
+// convertValue returns the value as T, numeric values are converted
+// to the numeric T, if they fit into it.
+func convertValue[T any](v slog.Value) (res T, ok bool) {
+	if res, ok = v.Any().(T); ok {
+		return res, true
+	}
+
+	rv := reflect.ValueOf(&res).Elem()
+	switch v.Kind() {
+	case slog.KindInt64:
+		n := v.Int64()
+		switch {
+		case rv.CanInt() && !rv.OverflowInt(n):
+			rv.SetInt(n)
+		case rv.CanUint() && n >= 0 && !rv.OverflowUint(uint64(n)):
+			rv.SetUint(uint64(n))
+		default:
+			return res, false
+		}
+	case slog.KindUint64:
+		n := v.Uint64()
+		switch {
+		case rv.CanUint() && !rv.OverflowUint(n):
+			rv.SetUint(n)
+		case rv.CanInt() && n <= math.MaxInt64 && !rv.OverflowInt(int64(n)):
+			rv.SetInt(int64(n))
+		default:
+			return res, false
+		}
+	case slog.KindFloat64:
+		f := v.Float64()
+		if !rv.CanFloat() || rv.OverflowFloat(f) {
+			return res, false
+		}
+		rv.SetFloat(f)
+	default:
+		return res, false
+	}
+	return res, true
+}
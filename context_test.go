@@ -0,0 +1,110 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrsFromContext(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, AttrsFromContext(context.Background()))
+		ctx := ContextWithAttrs(context.Background())
+		assert.Empty(t, AttrsFromContext(ctx))
+	})
+
+	t.Run("nearest wins", func(t *testing.T) {
+		ctx := ContextWithAttrs(context.Background(), slog.String("a", "1"), slog.String("b", "2"))
+		child := ContextWithAttrs(ctx, slog.String("c", "3"), slog.String("a", "4"))
+
+		assert.Equal(t, []slog.Attr{
+			slog.String("a", "4"),
+			slog.String("b", "2"),
+			slog.String("c", "3"),
+		}, AttrsFromContext(child))
+
+		assert.Equal(t, []slog.Attr{
+			slog.String("a", "1"),
+			slog.String("b", "2"),
+		}, AttrsFromContext(ctx), "parent must not be affected")
+	})
+
+	t.Run("groups are merged", func(t *testing.T) {
+		ctx := ContextWithAttrs(context.Background(),
+			slog.Group("g", slog.String("a", "1"), slog.Group("sub", slog.String("b", "2"))))
+		ctx = ContextWithAttrs(ctx, slog.Group("g", slog.Group("sub", slog.String("b", "3"), slog.String("c", "4"))))
+		ctx = ContextWithAttrs(ctx, slog.String("a", "5"))
+
+		assert.Equal(t, []slog.Attr{
+			slog.Group("g", slog.String("a", "1"), slog.Group("sub", slog.String("b", "3"), slog.String("c", "4"))),
+			slog.String("a", "5"),
+		}, AttrsFromContext(ctx))
+	})
+}
+
+func TestContextField(t *testing.T) {
+	userID := NewContextField[int]("user_id")
+	assert.Equal(t, "user_id", userID.Key())
+
+	_, ok := userID.Value(context.Background())
+	assert.False(t, ok)
+
+	ctx := userID.With(context.Background(), 1)
+	ctx = ContextWithAttrs(ctx, slog.String("other", "value"))
+	v, ok := userID.Value(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	child := userID.With(ctx, 2)
+	v, ok = userID.Value(child)
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+	assert.Equal(t, []slog.Attr{slog.Int("user_id", 2), slog.String("other", "value")}, AttrsFromContext(child))
+
+	t.Run("set as plain attribute", func(t *testing.T) {
+		name := NewContextField[string]("name")
+		ctx := ContextWithAttrs(context.Background(), slog.String("name", "plain"))
+		v, ok := name.Value(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "plain", v)
+
+		_, ok = NewContextField[int]("name").Value(ctx)
+		assert.False(t, ok, "type mismatch")
+	})
+
+	t.Run("numeric conversion", func(t *testing.T) {
+		type userID int
+		ctx := ContextWithAttrs(context.Background(), slog.Int("n", 300), slog.Uint64("u", 7), slog.Float64("f", 0.5))
+
+		n, ok := NewContextField[int]("n").Value(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, 300, n)
+
+		id, ok := NewContextField[userID]("n").Value(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, userID(300), id)
+
+		u, ok := NewContextField[uint16]("n").Value(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, uint16(300), u)
+
+		_, ok = NewContextField[int8]("n").Value(ctx)
+		assert.False(t, ok, "overflow")
+
+		i, ok := NewContextField[int]("u").Value(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, 7, i)
+
+		f, ok := NewContextField[float32]("f").Value(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, float32(0.5), f)
+
+		_, ok = NewContextField[int]("f").Value(ctx)
+		assert.False(t, ok, "float to int")
+
+		_, ok = NewContextField[string]("n").Value(ctx)
+		assert.False(t, ok, "number to string")
+	})
+}
@@ -0,0 +1,171 @@
+package slogx
+
+import (
+	"log/slog"
+	"reflect"
+)
+
+// MapAttrs returns the record with its attributes replaced by the result of
+// fn, while the time, level, message and PC are kept intact.
+//
+// Like slog.HandlerOptions.ReplaceAttr, fn is called with the resolved values
+// of all non-group attributes, including the ones inside groups, with the
+// path of their groups (groups with empty keys are not in the path), and it
+// must not retain the path. The returned attribute replaces the given one:
+//   - the empty slog.Attr{} removes the attribute;
+//   - the group with the empty key inserts its attributes in place of the
+//     given one, e.g. slog.Group("", attr, slog.Int("extra", 1)) inserts
+//     a new attribute after the given one.
+//
+// If fn returns all the attributes unchanged, the same record is returned
+// without any allocations (except for the path of groups, if there are any),
+// otherwise, a new record is built, so the original one may be kept
+// by the caller.
+func MapAttrs(rec slog.Record, fn func(groups []string, attr slog.Attr) slog.Attr) slog.Record {
+	var (
+		res     []slog.Attr
+		groups  []string
+		changed bool
+		idx     int
+	)
+
+	rec.Attrs(func(attr slog.Attr) bool {
+		nattr, ok := mapAttr(&groups, attr, fn)
+		if ok && !changed {
+			changed = true
+			res = make([]slog.Attr, 0, rec.NumAttrs()+1)
+			rec.Attrs(func(attr slog.Attr) bool {
+				if len(res) == idx {
+					return false
+				}
+				res = append(res, attr)
+				return true
+			})
+		}
+
+		switch {
+		case ok:
+			res = appendMapped(res, nattr)
+		case changed:
+			res = append(res, nattr)
+		}
+
+		idx++
+		return true
+	})
+
+	if !changed {
+		return rec
+	}
+
+	nrec := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	nrec.AddAttrs(res...)
+	return nrec
+}
+
+// mapAttr applies fn to the attribute, or to the attributes inside it, if it is
+// a group, and reports whether the attribute was changed. Unchanged attributes
+// are returned as is, without resolving their values.
+func mapAttr(groups *[]string, attr slog.Attr, fn func([]string, slog.Attr) slog.Attr) (slog.Attr, bool) {
+	val := attr.Value.Resolve()
+
+	if val.Kind() != slog.KindGroup {
+		resolved := slog.Attr{Key: attr.Key, Value: val}
+		nattr := fn(*groups, resolved)
+		if nattr.Key == resolved.Key && sameValue(nattr.Value, resolved.Value) {
+			return attr, false
+		}
+		return nattr, true
+	}
+
+	if attr.Key != "" {
+		*groups = append(*groups, attr.Key)
+		defer func() { *groups = (*groups)[:len(*groups)-1] }()
+	}
+
+	var (
+		res     []slog.Attr
+		changed bool
+	)
+
+	group := val.Group()
+	for i, a := range group {
+		na, ok := mapAttr(groups, a, fn)
+		if ok && !changed {
+			changed = true
+			res = append(make([]slog.Attr, 0, len(group)+1), group[:i]...)
+		}
+		switch {
+		case ok:
+			res = appendMapped(res, na)
+		case changed:
+			res = append(res, na)
+		}
+	}
+
+	if !changed {
+		return attr, false
+	}
+
+	return slog.Attr{Key: attr.Key, Value: slog.GroupValue(res...)}, true
+}
+
+// appendMapped appends the changed attribute to the attributes, dropping
+// the empty attributes and inlining the groups with empty keys.
+func appendMapped(attrs []slog.Attr, attr slog.Attr) []slog.Attr {
+	switch {
+	case attr.Key == "" && attr.Value.Kind() == slog.KindGroup:
+		return append(attrs, attr.Value.Group()...)
+	case attr.Key == "" && attr.Value.Kind() == slog.KindAny && attr.Value.Any() == nil:
+		return attrs
+	default:
+		return append(attrs, attr)
+	}
+}
+
+// sameValue reports whether the values are the same, without panicking on
+// the values of uncomparable types: slices and maps are the same if they
+// refer to the same data, other uncomparable values are never the same.
+func sameValue(a, b slog.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+
+	switch a.Kind() {
+	case slog.KindAny, slog.KindLogValuer:
+		return sameAny(a.Any(), b.Any())
+	case slog.KindGroup:
+		return false
+	default:
+		return a.Equal(b)
+	}
+}
+
+func sameAny(x, y any) (same bool) {
+	t := reflect.TypeOf(x)
+	if t != reflect.TypeOf(y) {
+		return false
+	}
+
+	if t == nil {
+		return true
+	}
+
+	switch xv, yv := reflect.ValueOf(x), reflect.ValueOf(y); {
+	case t.Kind() == reflect.Slice:
+		return xv.Pointer() == yv.Pointer() && xv.Len() == yv.Len()
+	case t.Kind() == reflect.Map || t.Kind() == reflect.Func:
+		return xv.Pointer() == yv.Pointer()
+	case !t.Comparable():
+		return false
+	}
+
+	// comparable types may still hold uncomparable values in interface fields
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+
+	return x == y
+}
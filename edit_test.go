@@ -0,0 +1,145 @@
+package slogx
+
+import (
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type uncomparable struct {
+	v any
+}
+
+func TestMapAttrs(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	tm := time.Date(2024, 1, 13, 15, 20, 26, 0, time.UTC)
+
+	newRecord := func(attrs ...slog.Attr) slog.Record {
+		rec := slog.NewRecord(tm, slog.LevelWarn, "message", pcs[0])
+		rec.AddAttrs(attrs...)
+		return rec
+	}
+
+	attrs := []slog.Attr{
+		slog.String("a", "1"),
+		slog.Group("g", slog.String("b", "2"), slog.Group("h", slog.String("c", "3"))),
+		slog.Group("", slog.String("inline", "4")),
+	}
+
+	t.Run("groups path", func(t *testing.T) {
+		var paths []string
+		MapAttrs(newRecord(attrs...), func(groups []string, attr slog.Attr) slog.Attr {
+			paths = append(paths, groupsKey(groups, attr.Key))
+			return attr
+		})
+		assert.Equal(t, []string{"a", "g.b", "g.h.c", "inline"}, paths)
+	})
+
+	t.Run("replace nested", func(t *testing.T) {
+		rec := MapAttrs(newRecord(attrs...), func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == "c" {
+				return slog.Int("c", 3)
+			}
+			return attr
+		})
+
+		assert.Equal(t, tm, rec.Time)
+		assert.Equal(t, slog.LevelWarn, rec.Level)
+		assert.Equal(t, "message", rec.Message)
+		assert.Equal(t, pcs[0], rec.PC)
+		assert.Equal(t, []slog.Attr{
+			slog.String("a", "1"),
+			slog.Group("g", slog.String("b", "2"), slog.Group("h", slog.Int("c", 3))),
+			slog.Group("", slog.String("inline", "4")),
+		}, Attrs(rec))
+	})
+
+	t.Run("remove and insert", func(t *testing.T) {
+		rec := MapAttrs(newRecord(attrs...), func(groups []string, attr slog.Attr) slog.Attr {
+			switch attr.Key {
+			case "a":
+				return slog.Attr{}
+			case "b":
+				return slog.Group("", attr, slog.String("b2", "inserted"))
+			}
+			return attr
+		})
+
+		assert.Equal(t, []slog.Attr{
+			slog.Group("g",
+				slog.String("b", "2"), slog.String("b2", "inserted"),
+				slog.Group("h", slog.String("c", "3")),
+			),
+			slog.Group("", slog.String("inline", "4")),
+		}, Attrs(rec))
+	})
+
+	t.Run("original record is intact", func(t *testing.T) {
+		orig := newRecord(slog.String("a", "1"))
+		rec := MapAttrs(orig, func(_ []string, attr slog.Attr) slog.Attr { return slog.String("a", "2") })
+		rec.AddAttrs(slog.String("b", "3"))
+
+		assert.Equal(t, []slog.Attr{slog.String("a", "1")}, Attrs(orig))
+		assert.Equal(t, []slog.Attr{slog.String("a", "2"), slog.String("b", "3")}, Attrs(rec))
+	})
+
+	t.Run("log valuers are resolved", func(t *testing.T) {
+		var seen slog.Value
+		rec := newRecord(slog.Any("v", structuredError{error: assert.AnError}), slog.Any("e", ErrorValue(assert.AnError)))
+		res := MapAttrs(rec, func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == "msg" && len(groups) == 1 && groups[0] == "v" {
+				seen = attr.Value
+			}
+			return attr
+		})
+		assert.Equal(t, assert.AnError.Error(), seen.String())
+		assert.Equal(t, Attrs(rec), Attrs(res))
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		bs := []byte("bytes")
+		rec := newRecord(
+			slog.String("a", "1"),
+			slog.Any("bytes", bs),
+			slog.Any("map", map[string]int{"a": 1}),
+			slog.Any("struct", uncomparable{v: 1}),
+			slog.Time("time", tm),
+			slog.Duration("duration", time.Second),
+		)
+
+		identity := func(_ []string, attr slog.Attr) slog.Attr { return attr }
+		res := MapAttrs(rec, identity)
+		assert.Equal(t, Attrs(rec), Attrs(res))
+
+		allocs := testing.AllocsPerRun(100, func() { _ = MapAttrs(rec, identity) })
+		assert.Zero(t, allocs)
+	})
+}
+
+func TestSameValue(t *testing.T) {
+	bs := []byte("bytes")
+	m := map[string]int{"a": 1}
+
+	assert.True(t, sameValue(slog.AnyValue(bs), slog.AnyValue(bs)))
+	assert.False(t, sameValue(slog.AnyValue(bs), slog.AnyValue([]byte("bytes"))))
+	assert.False(t, sameValue(slog.AnyValue(bs), slog.AnyValue(bs[:2])))
+	assert.True(t, sameValue(slog.AnyValue(m), slog.AnyValue(m)))
+	assert.False(t, sameValue(slog.AnyValue(m), slog.AnyValue(map[string]int{"a": 1})))
+	assert.False(t, sameValue(slog.AnyValue(uncomparable{v: []int{1}}), slog.AnyValue(uncomparable{v: []int{1}})))
+	assert.True(t, sameValue(slog.AnyValue(uncomparable{v: 1}), slog.AnyValue(uncomparable{v: 1})))
+	assert.False(t, sameValue(slog.StringValue("1"), slog.IntValue(1)))
+	assert.True(t, sameValue(slog.StringValue("1"), slog.StringValue("1")))
+	assert.True(t, sameValue(slog.AnyValue(nil), slog.AnyValue(nil)))
+}
+
+func groupsKey(groups []string, key string) string {
+	res := ""
+	for _, g := range groups {
+		res += g + "."
+	}
+	return res + key
+}
@@ -0,0 +1,238 @@
+package slogx
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrFormat specifies how Error renders errors.
+type ErrFormat uint8
+
+const (
+	// ErrFormatText means that the error is logged as is, i.e. handlers
+	// usually print only the result of its Error method.
+	ErrFormatText ErrFormat = iota
+	// ErrFormatStructured means that the error is logged as a group,
+	// built by ErrorValue.
+	ErrFormatStructured
+)
+
+// ErrAttrFormat specifies how Error renders errors, ErrAttrStrategy
+// is respected in both formats.
+// Example of the structured error, rendered by fblog:
+//
+//	            error.msg: "read config: open config.yaml: no such file or directory"
+//	           error.type: "*fmt.wrapError"
+//	      error.cause.msg: "open config.yaml: no such file or directory"
+//	     error.cause.type: "*fs.PathError"
+//	error.cause.cause.msg: "no such file or directory"
+//	                  ...
+var ErrAttrFormat = ErrFormatText
+
+// maxErrDepth limits the depth of the rendered tree of causes, in case
+// of the cyclic or extremely deep chains.
+const maxErrDepth = 32
+
+// ErrorValue returns the structured representation of the error:
+// a group with the message ("msg"), Go type ("type") and stack trace
+// ("stacktrace"), if the error exposes it, along with the cause ("cause"),
+// if the error wraps a single one with Unwrap() error, or the causes
+// ("causes", indexed from 0), if it wraps multiple ones with Unwrap() []error,
+// rendered in the same way.
+//
+// The stack trace is taken from StackTrace() method, that returns either
+// a string or a slice of program counters (e.g. the one of
+// github.com/pkg/errors), or Callers() []uintptr method, or the "%+v"
+// representation of the error, that implements fmt.Formatter and
+// doesn't wrap other errors.
+func ErrorValue(err error) slog.Value {
+	return errorValue(err, 0)
+}
+
+func errorValue(err error, depth int) slog.Value {
+	if err == nil {
+		return slog.AnyValue(nil)
+	}
+
+	attrs := []slog.Attr{
+		slog.String("msg", err.Error()),
+		slog.String("type", fmt.Sprintf("%T", err)),
+	}
+
+	var (
+		causes []error
+		joined bool
+	)
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			causes = []error{cause}
+		}
+	case interface{ Unwrap() []error }:
+		causes, joined = e.Unwrap(), true
+	}
+
+	if st := stackTrace(err, len(causes) > 0); len(st) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "stacktrace", Value: indexedValue(st)})
+	}
+
+	if depth >= maxErrDepth {
+		return slog.GroupValue(attrs...)
+	}
+
+	switch {
+	case len(causes) == 1 && !joined:
+		attrs = append(attrs, slog.Attr{Key: "cause", Value: errorValue(causes[0], depth+1)})
+	case len(causes) > 0:
+		vals := make([]slog.Attr, len(causes))
+		for i, cause := range causes {
+			vals[i] = slog.Attr{Key: strconv.Itoa(i), Value: errorValue(cause, depth+1)}
+		}
+		attrs = append(attrs, slog.Attr{Key: "causes", Value: slog.GroupValue(vals...)})
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// stackTrace returns the lines of the stack trace, exposed by the error itself.
+func stackTrace(err error, wraps bool) []string {
+	if e, ok := err.(interface{ Callers() []uintptr }); ok {
+		return frames(e.Callers())
+	}
+
+	if m := reflect.ValueOf(err).MethodByName("StackTrace"); m.IsValid() &&
+		m.Type().NumIn() == 0 && m.Type().NumOut() == 1 {
+		out := m.Call(nil)[0]
+		switch {
+		case out.Kind() == reflect.String:
+			return lines(out.String())
+		case out.Kind() == reflect.Slice && out.Type().Elem().Kind() == reflect.Uintptr:
+			pcs := make([]uintptr, out.Len())
+			for i := range pcs {
+				pcs[i] = uintptr(out.Index(i).Uint())
+			}
+			return frames(pcs)
+		}
+	}
+
+	// "%+v" of the wrapping errors usually contains the ones of causes,
+	// which are rendered on their own
+	if f, ok := err.(fmt.Formatter); ok && !wraps {
+		s := fmt.Sprintf("%+v", f)
+		return lines(strings.TrimPrefix(s, err.Error()))
+	}
+
+	return nil
+}
+
+func frames(pcs []uintptr) []string {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	var res []string
+	frs := runtime.CallersFrames(pcs)
+	for {
+		fr, more := frs.Next()
+		res = append(res, fmt.Sprintf("%s %s:%d", fr.Function, fr.File, fr.Line))
+		if !more {
+			break
+		}
+	}
+	return res
+}
+
+func lines(s string) []string {
+	var res []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+func indexedValue(ss []string) slog.Value {
+	attrs := make([]slog.Attr, len(ss))
+	for i, s := range ss {
+		attrs[i] = slog.String(strconv.Itoa(i), s)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// structuredError renders the error with ErrorValue on resolving,
+// while keeping it available to errors.Is and errors.As.
+type structuredError struct{ error }
+
+// LogValue implements slog.LogValuer.
+func (e structuredError) LogValue() slog.Value { return ErrorValue(e.error) }
+
+// Unwrap returns the wrapped error.
+func (e structuredError) Unwrap() error { return e.error }
+
+// UnwrapStructured returns the original error, if err is the one, wrapped
+// by Error to be rendered in ErrFormatStructured, otherwise err as is.
+func UnwrapStructured(err error) error {
+	if e, ok := err.(structuredError); ok {
+		return e.error
+	}
+	return err
+}
+
+// ErrorWithAttrs is an error, that carries the attributes, describing
+// the context it occurred in, to the place, where it is logged.
+type ErrorWithAttrs struct {
+	err   error
+	attrs []slog.Attr
+}
+
+// WrapErr returns the error with the given attributes attached,
+// or nil, if err is nil.
+func WrapErr(err error, attrs ...slog.Attr) error {
+	if err == nil {
+		return nil
+	}
+	return &ErrorWithAttrs{err: err, attrs: append([]slog.Attr(nil), attrs...)}
+}
+
+// Error returns the message of the wrapped error.
+func (e *ErrorWithAttrs) Error() string { return e.err.Error() }
+
+// Unwrap returns the wrapped error.
+func (e *ErrorWithAttrs) Unwrap() error { return e.err }
+
+// Attrs returns the attributes, attached to this error only.
+func (e *ErrorWithAttrs) Attrs() []slog.Attr { return e.attrs }
+
+// ErrorAttrs returns the attributes, attached with WrapErr to the error and
+// to the errors it wraps, along the whole tree of Unwrap() error and
+// Unwrap() []error methods. If the same key is attached more than once,
+// the outer error wins, groups with the same key are merged.
+func ErrorAttrs(err error) []slog.Attr {
+	return errorAttrs(err, 0)
+}
+
+func errorAttrs(err error, depth int) (res []slog.Attr) {
+	if err == nil || depth > maxErrDepth {
+		return nil
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		res = errorAttrs(e.Unwrap(), depth+1)
+	case interface{ Unwrap() []error }:
+		for _, cause := range e.Unwrap() {
+			res = mergeAttrs(res, errorAttrs(cause, depth+1))
+		}
+	}
+
+	if e, ok := err.(*ErrorWithAttrs); ok {
+		res = mergeAttrs(res, e.attrs)
+	}
+
+	return res
+}
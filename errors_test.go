@@ -0,0 +1,242 @@
+package slogx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// frame is like the one of github.com/pkg/errors
+type frame uintptr
+
+type pcsError struct{ pcs []frame }
+
+func (e pcsError) Error() string       { return "pcs error" }
+func (e pcsError) StackTrace() []frame { return e.pcs }
+
+func newPCsError() pcsError {
+	pcs := callersPCs()
+	frames := make([]frame, len(pcs))
+	for i, pc := range pcs {
+		frames[i] = frame(pc)
+	}
+	return pcsError{pcs: frames}
+}
+
+func callersPCs() []uintptr {
+	pcs := make([]uintptr, 32)
+	return pcs[:runtime.Callers(1, pcs)]
+}
+
+type callersError struct{ pcs []uintptr }
+
+func (e callersError) Error() string      { return "callers error" }
+func (e callersError) Callers() []uintptr { return e.pcs }
+
+type stringStackError struct{}
+
+func (stringStackError) Error() string      { return "string stack error" }
+func (stringStackError) StackTrace() string { return "main.f\n\tmain.go:10\n" }
+
+type formatterError struct{}
+
+func (formatterError) Error() string { return "formatter error" }
+func (e formatterError) Format(s fmt.State, verb rune) {
+	_, _ = io.WriteString(s, e.Error())
+	if s.Flag('+') {
+		_, _ = io.WriteString(s, "\nmain.f\n\tmain.go:10")
+	}
+}
+
+func groupMap(v slog.Value) map[string]any {
+	res := map[string]any{}
+	for _, a := range v.Resolve().Group() {
+		if a.Value.Kind() == slog.KindGroup {
+			res[a.Key] = groupMap(a.Value)
+			continue
+		}
+		res[a.Key] = a.Value.Any()
+	}
+	return res
+}
+
+func TestErrorValue(t *testing.T) {
+	t.Run("wrapped", func(t *testing.T) {
+		err := fmt.Errorf("read config: %w", fmt.Errorf("open: %w", io.EOF))
+		assert.Equal(t, map[string]any{
+			"msg":  "read config: open: EOF",
+			"type": "*fmt.wrapError",
+			"cause": map[string]any{
+				"msg":  "open: EOF",
+				"type": "*fmt.wrapError",
+				"cause": map[string]any{
+					"msg":  "EOF",
+					"type": "*errors.errorString",
+				},
+			},
+		}, groupMap(ErrorValue(err)))
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		err := errors.Join(io.EOF, fmt.Errorf("wrap: %w", io.ErrUnexpectedEOF))
+		assert.Equal(t, map[string]any{
+			"msg":  "EOF\nwrap: unexpected EOF",
+			"type": "*errors.joinError",
+			"causes": map[string]any{
+				"0": map[string]any{"msg": "EOF", "type": "*errors.errorString"},
+				"1": map[string]any{
+					"msg":  "wrap: unexpected EOF",
+					"type": "*fmt.wrapError",
+					"cause": map[string]any{
+						"msg":  "unexpected EOF",
+						"type": "*errors.errorString",
+					},
+				},
+			},
+		}, groupMap(ErrorValue(err)))
+	})
+
+	t.Run("multiple wrapped with Errorf", func(t *testing.T) {
+		err := fmt.Errorf("%w, %w", io.EOF, io.ErrClosedPipe)
+		m := groupMap(ErrorValue(err))
+		assert.Equal(t, "*fmt.wrapErrors", m["type"])
+		assert.Len(t, m["causes"], 2)
+	})
+
+	t.Run("stacktrace from program counters", func(t *testing.T) {
+		m := groupMap(ErrorValue(newPCsError()))
+		require.Contains(t, m, "stacktrace")
+		assert.Contains(t, m["stacktrace"].(map[string]any)["0"], "errors_test.go")
+	})
+
+	t.Run("stacktrace from callers", func(t *testing.T) {
+		m := groupMap(ErrorValue(callersError{pcs: callersPCs()}))
+		require.Contains(t, m, "stacktrace")
+		assert.Contains(t, m["stacktrace"].(map[string]any)["0"], "slogx.callersPCs")
+	})
+
+	t.Run("stacktrace from string", func(t *testing.T) {
+		m := groupMap(ErrorValue(stringStackError{}))
+		assert.Equal(t, map[string]any{"0": "main.f", "1": "main.go:10"}, m["stacktrace"])
+	})
+
+	t.Run("stacktrace from formatter", func(t *testing.T) {
+		m := groupMap(ErrorValue(formatterError{}))
+		assert.Equal(t, map[string]any{"0": "main.f", "1": "main.go:10"}, m["stacktrace"])
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, ErrorValue(nil).Any())
+	})
+}
+
+func TestError_Structured(t *testing.T) {
+	defer func(f ErrFormat, s LogAttrStrategy) { ErrAttrFormat, ErrAttrStrategy = f, s }(ErrAttrFormat, ErrAttrStrategy)
+	ErrAttrFormat = ErrFormatStructured
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", Error(fmt.Errorf("wrap: %w", io.EOF)))
+
+		var entry struct {
+			Error map[string]any `json:"error"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		assert.Equal(t, map[string]any{
+			"msg":   "wrap: EOF",
+			"type":  "*fmt.wrapError",
+			"cause": map[string]any{"msg": "EOF", "type": "*errors.errorString"},
+		}, entry.Error)
+	})
+
+	t.Run("keeps the error", func(t *testing.T) {
+		attr := Error(fmt.Errorf("wrap: %w", io.EOF))
+		err, ok := attr.Value.Any().(error)
+		require.True(t, ok)
+		assert.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, "wrap: EOF", err.Error())
+
+		orig := UnwrapStructured(err)
+		assert.IsType(t, fmt.Errorf("%w", io.EOF), orig)
+		assert.Equal(t, "wrap: EOF", orig.Error())
+
+		// other errors are returned as is
+		assert.Equal(t, orig, UnwrapStructured(orig))
+		assert.NoError(t, UnwrapStructured(nil))
+	})
+
+	t.Run("strategy", func(t *testing.T) {
+		ErrAttrStrategy = LogAttrNone
+		assert.Equal(t, slog.Attr{}, Error(nil))
+
+		ErrAttrStrategy = LogAttrAsIs
+		assert.Equal(t, slog.Any(ErrorKey, nil), Error(nil))
+	})
+}
+
+func TestWrapErr(t *testing.T) {
+	assert.NoError(t, WrapErr(nil, slog.String("a", "b")))
+
+	err := WrapErr(io.EOF, slog.String("path", "config.yaml"))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, "EOF", err.Error())
+
+	var ewa *ErrorWithAttrs
+	require.ErrorAs(t, err, &ewa)
+	assert.Equal(t, []slog.Attr{slog.String("path", "config.yaml")}, ewa.Attrs())
+}
+
+func TestErrorAttrs(t *testing.T) {
+	t.Run("chain", func(t *testing.T) {
+		err := WrapErr(io.EOF, slog.String("path", "config.yaml"), slog.Int("status", 500),
+			slog.Group("upstream", slog.String("host", "inner")))
+		err = fmt.Errorf("read: %w", err)
+		err = WrapErr(err, slog.String("user_id", "u1"), slog.Int("status", 503),
+			slog.Group("upstream", slog.Int("port", 80)))
+
+		assert.Equal(t, []slog.Attr{
+			slog.String("path", "config.yaml"),
+			slog.Int("status", 503),
+			slog.Group("upstream", slog.String("host", "inner"), slog.Int("port", 80)),
+			slog.String("user_id", "u1"),
+		}, ErrorAttrs(err))
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		err := errors.Join(
+			WrapErr(io.EOF, slog.String("a", "1")),
+			WrapErr(io.ErrUnexpectedEOF, slog.String("b", "2")),
+		)
+		err = WrapErr(err, slog.String("a", "outer"))
+		assert.Equal(t, []slog.Attr{slog.String("a", "outer"), slog.String("b", "2")}, ErrorAttrs(err))
+	})
+
+	t.Run("no attrs", func(t *testing.T) {
+		assert.Empty(t, ErrorAttrs(fmt.Errorf("wrap: %w", io.EOF)))
+		assert.Empty(t, ErrorAttrs(nil))
+	})
+}
+
+func TestError_WithAttrs(t *testing.T) {
+	err := fmt.Errorf("read: %w", WrapErr(io.EOF, slog.String("path", "config.yaml")))
+
+	attr := Error(err)
+	assert.Equal(t, "", attr.Key)
+	assert.Equal(t, []slog.Attr{slog.Any(ErrorKey, err), slog.String("path", "config.yaml")}, attr.Value.Group())
+
+	buf := &bytes.Buffer{}
+	slog.New(slog.NewJSONHandler(buf, nil)).Error("failed", attr)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "read: EOF", entry["error"])
+	assert.Equal(t, "config.yaml", entry["path"])
+}
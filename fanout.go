@@ -0,0 +1,99 @@
+package slogx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Fanout returns a handler that sends each record to every given handler.
+// It is enabled for the level if at least one of the handlers is enabled,
+// and each handler receives only the records it is enabled for.
+// Errors from the handlers are joined with errors.Join.
+func Fanout(hs ...slog.Handler) slog.Handler {
+	return &fanout{hs: hs}
+}
+
+type fanout struct{ hs []slog.Handler }
+
+// Enabled returns true if any of the handlers is enabled for the level.
+func (f *fanout) Enabled(ctx context.Context, lvl slog.Level) bool {
+	for _, h := range f.hs {
+		if h.Enabled(ctx, lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes the copy of the record to each enabled handler.
+func (f *fanout) Handle(ctx context.Context, rec slog.Record) error {
+	var errs []error
+	for i, h := range f.hs {
+		if !h.Enabled(ctx, rec.Level) {
+			continue
+		}
+
+		// record is cloned, as handlers (and middlewares) may add attributes
+		// to it, which will share the same backing array otherwise
+		if err := h.Handle(ctx, rec.Clone()); err != nil {
+			errs = append(errs, fmt.Errorf("fanout handler #%d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs returns a new fanout with the given attributes applied to each handler.
+func (f *fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hs := make([]slog.Handler, len(f.hs))
+	for i, h := range f.hs {
+		hs[i] = h.WithAttrs(attrs)
+	}
+	return &fanout{hs: hs}
+}
+
+// WithGroup returns a new fanout with the given group applied to each handler.
+func (f *fanout) WithGroup(name string) slog.Handler {
+	hs := make([]slog.Handler, len(f.hs))
+	for i, h := range f.hs {
+		hs[i] = h.WithGroup(name)
+	}
+	return &fanout{hs: hs}
+}
+
+// Branch returns a handler, that accepts only records with the level not less
+// than lvl and passes them through the given middlewares to h.
+// It is meant to be used as one of the Fanout handlers, to give each of them
+// its own minimum level and middleware stack.
+// If lvl is nil, the level check is left to h.
+func Branch(h slog.Handler, lvl slog.Leveler, mws ...Middleware) slog.Handler {
+	if len(mws) > 0 {
+		h = NewChain(h, mws...)
+	}
+	if lvl == nil {
+		return h
+	}
+	return &leveled{Handler: h, lvl: lvl}
+}
+
+type leveled struct {
+	slog.Handler
+	lvl slog.Leveler
+}
+
+// Enabled returns true if the level is not less than the minimum one
+// and the wrapped handler is enabled.
+func (l *leveled) Enabled(ctx context.Context, lvl slog.Level) bool {
+	return lvl >= l.lvl.Level() && l.Handler.Enabled(ctx, lvl)
+}
+
+// WithAttrs returns a new leveled handler with the given attributes.
+func (l *leveled) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &leveled{Handler: l.Handler.WithAttrs(attrs), lvl: l.lvl}
+}
+
+// WithGroup returns a new leveled handler with the given group.
+func (l *leveled) WithGroup(name string) slog.Handler {
+	return &leveled{Handler: l.Handler.WithGroup(name), lvl: l.lvl}
+}
@@ -0,0 +1,115 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/cappuccinotm/slogx/slogt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanout_Handle(t *testing.T) {
+	t.Run("levels per branch", func(t *testing.T) {
+		debugBuf, warnBuf := &bytes.Buffer{}, &bytes.Buffer{}
+		h := Fanout(
+			Branch(slog.NewJSONHandler(debugBuf, &slog.HandlerOptions{Level: slog.LevelDebug}), nil),
+			Branch(slog.NewJSONHandler(warnBuf, &slog.HandlerOptions{Level: slog.LevelDebug}), slog.LevelWarn),
+		)
+
+		lg := slog.New(h)
+		lg.Debug("debug message")
+		lg.Warn("warn message")
+
+		t.Log(debugBuf.String())
+		t.Log(warnBuf.String())
+
+		assert.Contains(t, debugBuf.String(), "debug message")
+		assert.Contains(t, debugBuf.String(), "warn message")
+		assert.NotContains(t, warnBuf.String(), "debug message")
+		assert.Contains(t, warnBuf.String(), "warn message")
+	})
+
+	t.Run("middlewares per branch", func(t *testing.T) {
+		buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+		h := Fanout(
+			Branch(slog.NewJSONHandler(buf1, nil), nil,
+				func(next HandleFunc) HandleFunc {
+					return func(ctx context.Context, rec slog.Record) error {
+						rec.AddAttrs(slog.String("branch", "first"))
+						return next(ctx, rec)
+					}
+				},
+			),
+			slog.NewJSONHandler(buf2, nil),
+		)
+
+		slog.New(h).Info("test", slog.String("a", "1"))
+
+		var entry1, entry2 map[string]any
+		require.NoError(t, json.NewDecoder(buf1).Decode(&entry1))
+		require.NoError(t, json.NewDecoder(buf2).Decode(&entry2))
+		assert.Equal(t, "first", entry1["branch"])
+		assert.Equal(t, "1", entry1["a"])
+		assert.NotContains(t, entry2, "branch")
+		assert.Equal(t, "1", entry2["a"])
+	})
+
+	t.Run("errors are joined", func(t *testing.T) {
+		called := false
+		h := Fanout(
+			slogt.HandlerFunc(func(context.Context, slog.Record) error { return errors.New("first") }),
+			slogt.HandlerFunc(func(context.Context, slog.Record) error { called = true; return nil }),
+			slogt.HandlerFunc(func(context.Context, slog.Record) error { return errors.New("third") }),
+		)
+
+		err := h.Handle(context.Background(), slog.Record{})
+		require.Error(t, err)
+		assert.True(t, called, "all handlers must be called")
+		assert.EqualError(t, err, "fanout handler #0: first\nfanout handler #2: third")
+	})
+}
+
+func TestFanout_Enabled(t *testing.T) {
+	h := Fanout(
+		Branch(NopHandler(), slog.LevelDebug),
+		Branch(slog.NewJSONHandler(&bytes.Buffer{}, nil), slog.LevelWarn),
+	)
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+
+	lvl := &slog.LevelVar{}
+	lvl.Set(slog.LevelError)
+	h = Fanout(Branch(slog.NewJSONHandler(&bytes.Buffer{}, nil), lvl))
+	assert.False(t, h.Enabled(context.Background(), slog.LevelWarn))
+	lvl.Set(slog.LevelWarn)
+	assert.True(t, h.Enabled(context.Background(), slog.LevelWarn))
+}
+
+func TestFanout_WithAttrsAndGroup(t *testing.T) {
+	buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+	h := Fanout(
+		Branch(slog.NewJSONHandler(buf1, nil), slog.LevelInfo),
+		slog.NewJSONHandler(buf2, nil),
+	)
+
+	slog.New(h).With(slog.String("a", "1")).WithGroup("g").Info("test", slog.String("b", "2"))
+
+	for _, buf := range []*bytes.Buffer{buf1, buf2} {
+		t.Log(buf.String())
+
+		var entry struct {
+			A string `json:"a"`
+			G struct {
+				B string `json:"b"`
+			} `json:"g"`
+		}
+		require.NoError(t, json.NewDecoder(buf).Decode(&entry))
+		assert.Equal(t, "1", entry.A)
+		assert.Equal(t, "2", entry.G.B)
+	}
+}
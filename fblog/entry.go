@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/cappuccinotm/slogx/fblog/internal/misc"
+	"github.com/cappuccinotm/slogx/internal/levels"
 )
 
 type entry struct {
@@ -53,18 +54,13 @@ func (e *entry) WriteHeader(logTimeFmt string, maxKeySize int, rec slog.Record)
 		e.buf.WriteString(" ")
 	}
 
-	switch rec.Level {
-	case slog.LevelDebug:
-		e.buf.WriteString("[DEBUG]")
-	case slog.LevelInfo:
-		e.buf.WriteString(" [INFO]")
-	case slog.LevelWarn:
-		e.buf.WriteString(" [WARN]")
-	case slog.LevelError:
-		e.buf.WriteString("[ERROR]")
-	default:
-		e.buf.WriteString("[UNKNW]")
+	name := levels.Name(rec.Level)
+	if pad := lvlSize - len(name) - 2; pad > 0 {
+		e.spaces(pad, false)
 	}
+	e.buf.WriteString("[")
+	e.buf.WriteString(name)
+	e.buf.WriteString("]")
 
 	e.headerLen = e.buf.Len()
 	e.buf.WriteString(": ")
@@ -88,8 +84,13 @@ func (e *entry) WriteAttr(group []string, a slog.Attr) {
 		attr.Value = attr.Value.Resolve() // resolve the value before writing
 
 		if attr.Value.Kind() == slog.KindGroup {
+			// groups with empty keys are inlined
+			if attr.Key != "" {
+				// copy the groups to not share the backing array with the sibling groups
+				groups = append(groups[:len(groups):len(groups)], attr.Key)
+			}
 			for _, a := range attr.Value.Group() {
-				e.q.PushBack(grouped{group: append(groups, attr.Key), attr: a})
+				e.q.PushBack(grouped{group: groups, attr: a})
 			}
 			continue
 		}
@@ -0,0 +1,59 @@
+package fblog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_StructuredError(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	lg := slog.New(NewHandler(Out(buf), Err(buf)))
+
+	err := errors.Join(io.EOF, fmt.Errorf("wrap: %w", io.ErrUnexpectedEOF))
+	lg.Info("info message", slog.Attr{Key: "error", Value: slogx.ErrorValue(err)})
+
+	const expected = `
+2006-01-02 15:04:05  [INFO]: info message
+                  error.msg: "EOF\nwrap: unexpected EOF"
+                 error.type: "*errors.joinError"
+         error.causes.0.msg: "EOF"
+        error.causes.0.type: "*errors.errorString"
+         error.causes.1.msg: "wrap: unexpected EOF"
+        error.causes.1.type: "*fmt.wrapError"
+   error.causes.1.cause.msg: "unexpected EOF"
+  error.causes.1.cause.type: "*errors.errorString"
+`
+	assert.Equal(t, expected[1:], correctTimestamps(buf.String()))
+}
+
+func TestEntry_WriteAttr_SiblingGroups(t *testing.T) {
+	e := newEntry("", func(_ []string, a slog.Attr) slog.Attr { return a }, 1)
+	e.headerLen = 15
+	e.WriteAttr([]string{"a", "b", "c", "d"}, slog.Group("e", slog.Group("f",
+		slog.Group("g", slog.Int("x", 1)),
+		slog.Group("h", slog.Int("y", 2)),
+	)))
+	assert.Equal(t, "a.b.c.d.e.f.g.x: 1\na.b.c.d.e.f.h.y: 2\n", e.buf.String())
+}
+
+func TestHandler_InlineGroup(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	lg := slog.New(NewHandler(Out(buf), Err(buf)))
+
+	err := fmt.Errorf("read: %w", slogx.WrapErr(io.EOF, slog.String("path", "config.yaml")))
+	lg.Info("info message", slog.Group("g", slogx.Error(err)))
+
+	const expected = `
+2006-01-02 15:04:05  [INFO]: info message
+                    g.error: read: EOF
+                     g.path: "config.yaml"
+`
+	assert.Equal(t, expected[1:], correctTimestamps(buf.String()))
+}
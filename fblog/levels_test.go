@@ -0,0 +1,29 @@
+package fblog
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_CustomLevels(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	lg := slog.New(NewHandler(Out(buf), Err(buf), WithLevel(slogx.LevelTrace)))
+
+	lg.Log(context.Background(), slogx.LevelTrace, "trace message")
+	lg.Log(context.Background(), slogx.LevelNotice, "notice message")
+	lg.Log(context.Background(), slog.LevelInfo+1, "info+1 message")
+	lg.Log(context.Background(), slogx.LevelCritical, "critical message")
+
+	const expected = `
+2006-01-02 15:04:05 [TRACE]: trace message
+2006-01-02 15:04:05 [NOTICE]: notice message
+2006-01-02 15:04:05 [INFO+1]: info+1 message
+2006-01-02 15:04:05 [CRITICAL]: critical message
+`
+	assert.Equal(t, expected[1:], correctTimestamps(buf.String()))
+}
@@ -19,7 +19,7 @@ import (
 type Handler struct {
 	out, err io.Writer
 
-	lvl        slog.Level
+	lvl        slog.Leveler
 	srcFormat  SourceFormat
 	rep        func([]string, slog.Attr) slog.Attr
 	maxKeySize int
@@ -61,7 +61,7 @@ func NewHandler(opts ...Option) *Handler {
 }
 
 // Enabled returns true if the level is enabled.
-func (h *Handler) Enabled(_ context.Context, level slog.Level) bool { return h.lvl <= level }
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool { return h.lvl.Level() <= level }
 
 // Handle writes the record to the writer.
 func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
@@ -9,7 +9,8 @@ import (
 type Option func(*Handler)
 
 // WithLevel returns an Option that sets the level of the handler.
-func WithLevel(lvl slog.Level) Option { return func(h *Handler) { h.lvl = lvl } }
+// The level may be changed at runtime, if it's a slog.LevelVar.
+func WithLevel(lvl slog.Leveler) Option { return func(h *Handler) { h.lvl = lvl } }
 
 // SourceFormat is the source format of the handler.
 type SourceFormat uint8
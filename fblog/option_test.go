@@ -0,0 +1,20 @@
+package fblog
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLevel(t *testing.T) {
+	lvl := &slog.LevelVar{}
+	lvl.Set(slog.LevelWarn)
+	h := NewHandler(WithLevel(lvl))
+	assert.False(t, h.Enabled(context.Background(), slog.LevelInfo))
+
+	lvl.Set(slog.LevelInfo)
+	assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+}
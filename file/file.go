@@ -0,0 +1,459 @@
+// Package file provides a writer for log files, that rotates them by size
+// and on time boundaries, keeps the limited amount of backups, compresses
+// them in the background and reopens the file on request, e.g. on SIGHUP
+// from logrotate. It is meant to be used as the output of slog handlers:
+//
+//	w, err := file.New("/var/log/app.log", file.MaxSize(100<<20), file.MaxBackups(7), file.Compress)
+//	if err != nil { ... }
+//	defer w.Close()
+//
+//	h := slog.NewJSONHandler(w, nil)
+package file
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the format of the time in the backup names,
+// lexicographical order of names matches the order of rotations.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+type options struct {
+	maxSize    int64
+	every      time.Duration
+	maxBackups int
+	compress   bool
+	perm       fs.FileMode
+	now        func() time.Time
+	fs         FS
+	onError    func(error)
+}
+
+// Option is a functional option for New.
+type Option func(*options)
+
+// MaxSize sets the maximum size of the file in bytes, the file is rotated
+// before the write, that would exceed it. By default, files are not rotated
+// by size.
+func MaxSize(n int64) Option { return func(o *options) { o.maxSize = n } }
+
+// RotateEvery makes the writer rotate the file on time boundaries, which are
+// multiples of d since the zero time, e.g. every hour or at midnight UTC
+// for 24h. By default, files are not rotated by time.
+func RotateEvery(d time.Duration) Option { return func(o *options) { o.every = d } }
+
+// MaxBackups sets the maximum amount of backups to keep, the oldest ones are
+// removed first. Zero, which is the default, keeps all the backups.
+func MaxBackups(n int) Option { return func(o *options) { o.maxBackups = n } }
+
+// Compress makes the writer gzip the backups in the background.
+func Compress(o *options) { o.compress = true }
+
+// Perm sets the permissions of the created files. Default is 0o644.
+func Perm(perm fs.FileMode) Option { return func(o *options) { o.perm = perm } }
+
+// WithClock sets the function to get the current time. Default is time.Now.
+func WithClock(now func() time.Time) Option { return func(o *options) { o.now = now } }
+
+// WithFS sets the filesystem to work with. Default is OS.
+func WithFS(fsys FS) Option { return func(o *options) { o.fs = fsys } }
+
+// OnError sets the function to report the errors of the background work,
+// i.e. compression and removal of the old backups. By default, they are
+// ignored.
+func OnError(fn func(error)) Option { return func(o *options) { o.onError = fn } }
+
+// Writer is an io.Writer to the log file, that rotates the file: renames it
+// to the backup with the time of rotation in its name, e.g. app.log becomes
+// app-2006-01-02T15-04-05.000.log, and opens the new one.
+// Writer is safe for concurrent use.
+type Writer struct {
+	path string
+	opts options
+
+	mu   sync.Mutex
+	f    File // nil, if the last open failed
+	size int64
+	next time.Time // time of the next rotation, if rotated by time
+
+	closed bool
+
+	bg      sync.Mutex // guards the background work
+	pending []string   // backups to compress
+	running bool
+	bgWg    sync.WaitGroup
+}
+
+// New opens the file at the path for appending, creating it if needed,
+// and returns the Writer to it.
+func New(path string, opts ...Option) (*Writer, error) {
+	o := options{perm: 0o644, now: time.Now, fs: OS{}, onError: func(error) {}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	w := &Writer{path: path, opts: o}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Write writes the bytes to the file, rotating it beforehand, if needed.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+
+	switch {
+	case w.f == nil: // the previous open failed, e.g. the disk was full
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	case w.shouldRotate(len(p)):
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate rotates the file regardless of its size and time.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+
+	return w.rotate()
+}
+
+// Reopen closes the file and opens it again by its path, without rotation,
+// e.g. after the file was moved by an external tool, like logrotate.
+// If the file was not opened after the previous failure, it is just opened.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return os.ErrClosed
+	}
+
+	var closeErr error
+	if w.f != nil {
+		if err := w.f.Close(); err != nil {
+			closeErr = fmt.Errorf("close file: %w", err)
+		}
+		w.f = nil
+	}
+
+	return errors.Join(closeErr, w.open())
+}
+
+// Close closes the file and waits for the background work to finish.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	var err error
+	if w.f != nil {
+		err = w.f.Close()
+		w.f = nil
+	}
+	w.mu.Unlock()
+
+	w.bgWg.Wait()
+
+	if err != nil {
+		return fmt.Errorf("close file: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) shouldRotate(n int) bool {
+	switch {
+	case w.opts.maxSize > 0 && w.size > 0 && w.size+int64(n) > w.opts.maxSize:
+		return true
+	case w.opts.every > 0 && !w.opts.now().Before(w.next):
+		return true
+	default:
+		return false
+	}
+}
+
+// open opens the file at the path. Must be called under the lock.
+func (w *Writer) open() error {
+	f, err := w.opts.fs.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, w.opts.perm)
+	if err != nil {
+		return fmt.Errorf("open file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat file: %w", err)
+	}
+
+	w.f, w.size = f, info.Size()
+
+	if w.opts.every > 0 {
+		// the existing file, written in the previous period, is rotated
+		// on the first write
+		from := w.opts.now()
+		if w.size > 0 {
+			from = info.ModTime()
+		}
+		w.next = from.Truncate(w.opts.every).Add(w.opts.every)
+	}
+
+	return nil
+}
+
+// rotate moves the file to the backup and opens the new one.
+// Must be called under the lock.
+func (w *Writer) rotate() error {
+	backup, err := w.backupName()
+	if err != nil {
+		return err
+	}
+
+	if w.f != nil {
+		err = w.f.Close()
+		w.f = nil // the next write opens the file, if the open below fails
+		if err != nil {
+			return fmt.Errorf("close file: %w", err)
+		}
+	}
+
+	moved := true
+	switch err = w.opts.fs.Rename(w.path, backup); {
+	case errors.Is(err, fs.ErrNotExist): // e.g. removed by an external tool
+		moved = false
+	case err != nil: // keep writing to the same file
+		return errors.Join(fmt.Errorf("rename file: %w", err), w.open())
+	}
+
+	if err = w.open(); err != nil {
+		return err
+	}
+
+	if w.opts.every > 0 {
+		w.next = w.opts.now().Truncate(w.opts.every).Add(w.opts.every)
+	}
+
+	w.bg.Lock()
+	defer w.bg.Unlock()
+
+	if moved && w.opts.compress {
+		w.pending = append(w.pending, backup)
+	}
+
+	if !w.running {
+		w.running = true
+		w.bgWg.Add(1)
+		go w.background()
+	}
+
+	return nil
+}
+
+// background compresses the pending backups in the order of rotations
+// and removes the old ones, until there is nothing left to do.
+func (w *Writer) background() {
+	defer w.bgWg.Done()
+
+	for {
+		w.bg.Lock()
+		pending := w.pending
+		w.pending = nil
+		w.bg.Unlock()
+
+		for _, backup := range pending {
+			if err := w.compress(backup); err != nil {
+				w.opts.onError(err)
+			}
+		}
+
+		if err := w.removeOld(); err != nil {
+			w.opts.onError(err)
+		}
+
+		w.bg.Lock()
+		if len(w.pending) == 0 {
+			w.running = false
+			w.bg.Unlock()
+			return
+		}
+		w.bg.Unlock()
+	}
+}
+
+// backupName returns the name of the backup, that doesn't exist yet.
+func (w *Writer) backupName() (string, error) {
+	prefix, ext := w.split()
+	ts := w.opts.now().Format(backupTimeFormat)
+
+	for i := 0; ; i++ {
+		name := prefix + ts + ext
+		if i > 0 {
+			name = fmt.Sprintf("%s%s.%d%s", prefix, ts, i, ext)
+		}
+
+		_, err := w.opts.fs.Stat(name)
+		_, gzErr := w.opts.fs.Stat(name + ".gz")
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && errors.Is(gzErr, fs.ErrNotExist):
+			return name, nil
+		case err != nil && !errors.Is(err, fs.ErrNotExist):
+			return "", fmt.Errorf("stat backup: %w", err)
+		}
+	}
+}
+
+// split returns the prefix of the backup names, including the directory,
+// and the extension of the file, e.g. "/var/log/app-" and ".log".
+func (w *Writer) split() (prefix, ext string) {
+	ext = filepath.Ext(w.path)
+	return strings.TrimSuffix(w.path, ext) + "-", ext
+}
+
+func (w *Writer) compress(backup string) (err error) {
+	src, err := w.opts.fs.OpenFile(backup, os.O_RDONLY, 0)
+	if errors.Is(err, fs.ErrNotExist) { // already removed as an old one
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := w.opts.fs.OpenFile(backup+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, w.opts.perm)
+	if err != nil {
+		return fmt.Errorf("create compressed backup: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = w.opts.fs.Remove(backup + ".gz")
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return fmt.Errorf("compress backup: %w", err)
+	}
+	if err = gz.Close(); err != nil {
+		return fmt.Errorf("compress backup: %w", err)
+	}
+	if err = dst.Close(); err != nil {
+		return fmt.Errorf("close compressed backup: %w", err)
+	}
+
+	if err := w.opts.fs.Remove(backup); err != nil {
+		return fmt.Errorf("remove compressed backup: %w", err)
+	}
+
+	return nil
+}
+
+// removeOld removes the oldest backups over the limit.
+func (w *Writer) removeOld() error {
+	if w.opts.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := w.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for len(backups) > w.opts.maxBackups {
+		if err := w.opts.fs.Remove(backups[0]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("remove backup: %w", err))
+		}
+		backups = backups[1:]
+	}
+
+	return errors.Join(errs...)
+}
+
+// backups returns the paths of the backups, the oldest first.
+func (w *Writer) backups() ([]string, error) {
+	prefix, ext := w.split()
+	dir, base := filepath.Dir(prefix), filepath.Base(prefix)
+
+	entries, err := w.opts.fs.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory: %w", err)
+	}
+
+	type backup struct {
+		ts   string
+		n    int // counter of the backups, rotated at the same time
+		path string
+	}
+
+	var res []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, base) {
+			continue
+		}
+
+		rest := strings.TrimSuffix(strings.TrimSuffix(name[len(base):], ".gz"), ext)
+		if len(rest) < len(backupTimeFormat) {
+			continue
+		}
+
+		b := backup{ts: rest[:len(backupTimeFormat)], path: filepath.Join(dir, name)}
+		if _, err := time.Parse(backupTimeFormat, b.ts); err != nil {
+			continue // not a backup
+		}
+
+		if cnt := rest[len(backupTimeFormat):]; cnt != "" {
+			if b.n, err = strconv.Atoi(strings.TrimPrefix(cnt, ".")); err != nil || cnt[0] != '.' {
+				continue
+			}
+		}
+
+		res = append(res, b)
+	}
+
+	slices.SortFunc(res, func(a, b backup) int {
+		if c := strings.Compare(a.ts, b.ts); c != 0 {
+			return c
+		}
+		return a.n - b.n
+	})
+
+	paths := make([]string, len(res))
+	for i, b := range res {
+		paths[i] = b.path
+	}
+	return paths, nil
+}
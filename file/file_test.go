@@ -0,0 +1,296 @@
+package file
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newClock() *clock { return &clock{now: time.Date(2026, 1, 2, 10, 30, 0, 0, time.UTC)} }
+
+// files returns the contents of the files in the directory by their names,
+// decompressing the gzipped ones.
+func files(t *testing.T, dir string) map[string]string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	res := map[string]string{}
+	for _, e := range entries {
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		require.NoError(t, err)
+
+		var r io.Reader = f
+		if strings.HasSuffix(e.Name(), ".gz") {
+			gz, err := gzip.NewReader(f)
+			require.NoError(t, err)
+			r = gz
+		}
+
+		b, err := io.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		res[e.Name()] = string(b)
+	}
+
+	return res
+}
+
+func write(t *testing.T, w io.Writer, s string) {
+	t.Helper()
+	_, err := io.WriteString(w, s)
+	require.NoError(t, err)
+}
+
+func TestWriter_MaxSize(t *testing.T) {
+	dir, clk := t.TempDir(), newClock()
+	w, err := New(filepath.Join(dir, "app.log"), MaxSize(10), WithClock(clk.Now))
+	require.NoError(t, err)
+
+	write(t, w, "first\n")
+	clk.Add(time.Second)
+	write(t, w, "second\n") // exceeds the limit, rotated before the write
+	write(t, w, "long line over the limit\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, map[string]string{
+		"app-2026-01-02T10-30-01.000.log":   "first\n",
+		"app-2026-01-02T10-30-01.000.1.log": "second\n",
+		"app.log":                           "long line over the limit\n",
+	}, files(t, dir))
+}
+
+func TestWriter_RotateEvery(t *testing.T) {
+	t.Run("boundaries", func(t *testing.T) {
+		dir, clk := t.TempDir(), newClock()
+		w, err := New(filepath.Join(dir, "app.log"), RotateEvery(time.Hour), WithClock(clk.Now))
+		require.NoError(t, err)
+
+		write(t, w, "10:30\n")
+		clk.Add(29 * time.Minute)
+		write(t, w, "10:59\n")
+		clk.Add(time.Minute)
+		write(t, w, "11:00\n")
+		clk.Add(2 * time.Hour)
+		write(t, w, "13:00\n")
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, map[string]string{
+			"app-2026-01-02T11-00-00.000.log": "10:30\n10:59\n",
+			"app-2026-01-02T13-00-00.000.log": "11:00\n",
+			"app.log":                         "13:00\n",
+		}, files(t, dir))
+	})
+
+	t.Run("existing file from the previous period", func(t *testing.T) {
+		dir, clk := t.TempDir(), newClock()
+		path := filepath.Join(dir, "app.log")
+		require.NoError(t, os.WriteFile(path, []byte("old\n"), 0o600))
+		require.NoError(t, os.Chtimes(path, clk.Now(), clk.Now().Add(-time.Hour)))
+
+		w, err := New(path, RotateEvery(time.Hour), WithClock(clk.Now))
+		require.NoError(t, err)
+		write(t, w, "new\n")
+		require.NoError(t, w.Close())
+
+		assert.Equal(t, map[string]string{
+			"app-2026-01-02T10-30-00.000.log": "old\n",
+			"app.log":                         "new\n",
+		}, files(t, dir))
+	})
+}
+
+func TestWriter_BackupsAndCompression(t *testing.T) {
+	dir, clk := t.TempDir(), newClock()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "app-notes.log"), []byte("not a backup"), 0o600))
+
+	var errs []error
+	w, err := New(filepath.Join(dir, "app.log"), MaxBackups(2), Compress, WithClock(clk.Now),
+		OnError(func(err error) { errs = append(errs, err) }))
+	require.NoError(t, err)
+
+	for i := range 4 {
+		write(t, w, fmt.Sprintf("line %d\n", i))
+		clk.Add(time.Second)
+		require.NoError(t, w.Rotate())
+	}
+	write(t, w, "line 4\n")
+	require.NoError(t, w.Close())
+
+	assert.Empty(t, errs)
+	assert.Equal(t, map[string]string{
+		"app-notes.log":                      "not a backup",
+		"app-2026-01-02T10-30-03.000.log.gz": "line 2\n",
+		"app-2026-01-02T10-30-04.000.log.gz": "line 3\n",
+		"app.log":                            "line 4\n",
+	}, files(t, dir))
+
+	_, err = w.Write([]byte("closed"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+	assert.ErrorIs(t, w.Rotate(), os.ErrClosed)
+	assert.ErrorIs(t, w.Reopen(), os.ErrClosed)
+	assert.NoError(t, w.Close())
+}
+
+func TestWriter_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := New(path)
+	require.NoError(t, err)
+
+	write(t, w, "before\n")
+	require.NoError(t, os.Rename(path, filepath.Join(dir, "app.log.1"))) // e.g. logrotate
+	write(t, w, "moved\n")
+	require.NoError(t, w.Reopen())
+	write(t, w, "after\n")
+	require.NoError(t, w.Close())
+
+	assert.Equal(t, map[string]string{
+		"app.log.1": "before\nmoved\n",
+		"app.log":   "after\n",
+	}, files(t, dir))
+}
+
+func TestWriter_Concurrent(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(filepath.Join(dir, "app.log"), MaxSize(512), Compress)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 100 {
+				_, err := fmt.Fprintf(w, "writer %d line %d\n", i, j)
+				assert.NoError(t, err)
+			}
+		})
+	}
+	wg.Wait()
+	require.NoError(t, w.Close())
+
+	var lines []string
+	for _, content := range files(t, dir) {
+		lines = append(lines, strings.Split(strings.TrimSuffix(content, "\n"), "\n")...)
+	}
+	sort.Strings(lines)
+
+	var expected []string
+	for i := range 8 {
+		for j := range 100 {
+			expected = append(expected, fmt.Sprintf("writer %d line %d", i, j))
+		}
+	}
+	sort.Strings(expected)
+
+	assert.Equal(t, expected, lines)
+}
+
+type failingFS struct {
+	OS
+	renameErr error
+	openErr   error
+}
+
+func (f *failingFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	if f.openErr != nil {
+		return nil, f.openErr
+	}
+	return f.OS.OpenFile(name, flag, perm)
+}
+
+func (f *failingFS) Rename(oldpath, newpath string) error {
+	if f.renameErr != nil {
+		return f.renameErr
+	}
+	return f.OS.Rename(oldpath, newpath)
+}
+
+func TestWriter_FSErrors(t *testing.T) {
+	dir := t.TempDir()
+	fsys := &failingFS{renameErr: errors.New("read-only")}
+
+	w, err := New(filepath.Join(dir, "app.log"), MaxSize(5), WithFS(fsys))
+	require.NoError(t, err)
+
+	write(t, w, "first\n")
+	_, err = w.Write([]byte("second\n"))
+	assert.ErrorContains(t, err, "rename file: read-only")
+
+	// the writer keeps writing to the same file
+	fsys.renameErr = nil
+	write(t, w, "third\n")
+	require.NoError(t, w.Close())
+
+	assert.Len(t, files(t, dir), 2)
+	assert.Equal(t, "third\n", files(t, dir)["app.log"])
+
+	_, err = New(filepath.Join(dir, "missing", "app.log"))
+	assert.ErrorContains(t, err, "open file")
+}
+
+func TestWriter_RecoversAfterOpenError(t *testing.T) {
+	dir := t.TempDir()
+	fsys := &failingFS{}
+
+	w, err := New(filepath.Join(dir, "app.log"), MaxSize(5), WithFS(fsys))
+	require.NoError(t, err)
+
+	write(t, w, "first\n")
+
+	fsys.openErr = errors.New("no space left on device")
+	_, err = w.Write([]byte("second\n"))
+	assert.ErrorContains(t, err, "open file: no space left on device")
+	_, err = w.Write([]byte("third\n"))
+	assert.ErrorContains(t, err, "open file: no space left on device")
+	assert.ErrorContains(t, w.Reopen(), "open file: no space left on device")
+
+	t.Run("write", func(t *testing.T) {
+		fsys.openErr = nil
+		write(t, w, "fourth\n")
+		assert.Equal(t, "fourth\n", files(t, dir)["app.log"])
+	})
+
+	t.Run("reopen", func(t *testing.T) {
+		require.NoError(t, w.Rotate())
+		fsys.openErr = errors.New("no space left on device")
+		assert.Error(t, w.Rotate())
+
+		fsys.openErr = nil
+		require.NoError(t, w.Reopen())
+		write(t, w, "fifth\n")
+		assert.Equal(t, "fifth\n", files(t, dir)["app.log"])
+	})
+
+	require.NoError(t, w.Close())
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package file
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ReopenOnSignal reopens the file on SIGHUP, e.g. from logrotate,
+// reporting the errors to the function, set with OnError.
+// Returned function stops handling the signal.
+func (w *Writer) ReopenOnSignal() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := w.Reopen(); err != nil {
+					w.opts.onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter_ReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := New(path)
+	require.NoError(t, err)
+	defer w.Close()
+
+	stop := w.ReopenOnSignal()
+	defer stop()
+
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,4 @@
+package file
+
+// ReopenOnSignal does nothing on Windows, as there is no SIGHUP signal.
+func (w *Writer) ReopenOnSignal() (stop func()) { return func() {} }
@@ -0,0 +1,208 @@
+// Package gelf provides a slog.Handler, that sends records to Graylog
+// in the GELF 1.1 format, e.g.:
+//
+//	{"version":"1.1","host":"host","short_message":"failed","timestamp":1767366245.123,
+//	 "level":3,"_request.method":"GET"}
+//
+// The message of the record is the short_message (or the full_message,
+// if it has several lines, with its first line as the short_message),
+// the level is mapped to the syslog severity with syslog.SeverityOf,
+// attributes are the additional fields with the "_" prefix, names of groups
+// are joined with the separator.
+package gelf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cappuccinotm/slogx/internal/grouped"
+	"github.com/cappuccinotm/slogx/syslog"
+)
+
+// Compression is the compression of the UDP messages.
+type Compression uint8
+
+// Supported compressions.
+const (
+	CompressGzip Compression = iota
+	CompressZlib
+	CompressNone
+)
+
+type options struct {
+	lvl         slog.Leveler
+	host        string
+	separator   string
+	compression Compression
+	chunkSize   int
+	timeout     time.Duration
+}
+
+// Option is a functional option for New.
+type Option func(*options)
+
+// WithLevel sets the minimum level of the records to send.
+// Default is slog.LevelInfo.
+func WithLevel(lvl slog.Leveler) Option { return func(o *options) { o.lvl = lvl } }
+
+// Host sets the host field of the messages. Default is os.Hostname.
+func Host(name string) Option { return func(o *options) { o.host = name } }
+
+// Separator sets the separator of the group names in the field names.
+// Default is ".".
+func Separator(sep string) Option { return func(o *options) { o.separator = sep } }
+
+// WithCompression sets the compression of the UDP messages.
+// Default is CompressGzip. Messages over TCP are never compressed.
+func WithCompression(c Compression) Option { return func(o *options) { o.compression = c } }
+
+// ChunkSize sets the maximum size of the UDP datagram, messages over it are
+// split into chunks. Default is 1420, which fits into the typical MTU.
+func ChunkSize(n int) Option { return func(o *options) { o.chunkSize = n } }
+
+// Timeout sets the timeout of dialing and writing to the server.
+// Default is 5 seconds.
+func Timeout(d time.Duration) Option { return func(o *options) { o.timeout = d } }
+
+// Handler sends records to Graylog.
+type Handler struct {
+	t     transport
+	opts  *options
+	attrs grouped.Attrs
+}
+
+// New connects to Graylog and returns the Handler, that sends records to it.
+// The network is "udp" (messages are compressed and chunked) or "tcp"
+// (messages are delimited with the null byte), the TCP connection is
+// reestablished on errors.
+func New(network, addr string, opts ...Option) (*Handler, error) {
+	o := &options{
+		lvl:         slog.LevelInfo,
+		separator:   ".",
+		compression: CompressGzip,
+		chunkSize:   1420,
+		timeout:     5 * time.Second,
+	}
+	o.host, _ = os.Hostname()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var (
+		t   transport
+		err error
+	)
+	switch network {
+	case "udp", "udp4", "udp6":
+		t, err = newUDPTransport(network, addr, o)
+	case "tcp", "tcp4", "tcp6":
+		t, err = newTCPTransport(network, addr, o.timeout)
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{t: t, opts: o}, nil
+}
+
+// Enabled reports whether the level is at or above the minimum one.
+func (h *Handler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.opts.lvl.Level()
+}
+
+// Handle sends the record to Graylog.
+func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
+	msg, err := json.Marshal(h.message(rec))
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+
+	return h.t.send(msg)
+}
+
+// WithAttrs returns a new Handler with the given attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	hh := *h
+	hh.attrs = h.attrs.WithAttrs(attrs)
+	return &hh
+}
+
+// WithGroup returns a new Handler with the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	hh := *h
+	hh.attrs = h.attrs.WithGroup(name)
+	return &hh
+}
+
+// Close closes the connection to Graylog.
+func (h *Handler) Close() error { return h.t.close() }
+
+func (h *Handler) message(rec slog.Record) map[string]any {
+	msg := map[string]any{
+		"version": "1.1",
+		"host":    h.opts.host,
+		"level":   syslog.SeverityOf(rec.Level),
+	}
+
+	if !rec.Time.IsZero() {
+		msg["timestamp"] = float64(rec.Time.UnixMilli()) / 1000
+	}
+
+	msg["short_message"] = rec.Message
+	if first, _, multiline := strings.Cut(rec.Message, "\n"); multiline {
+		msg["short_message"] = first
+		msg["full_message"] = rec.Message
+	}
+
+	h.attrs.Walk(rec, func(groups []string, a slog.Attr) {
+		name := "_" + invalidFieldChars.ReplaceAllString(strings.Join(append(slices.Clip(groups), a.Key), h.opts.separator), "_")
+		if name == "_id" { // reserved by Graylog
+			name = "__id"
+		}
+		msg[name] = fieldValue(a.Value)
+	})
+
+	return msg
+}
+
+// invalidFieldChars matches the characters, that are not allowed
+// in the names of additional fields.
+var invalidFieldChars = regexp.MustCompile(`[^\w.\-]`)
+
+// fieldValue returns the value of the additional field, which is either
+// a number or a string.
+func fieldValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return v.Uint64()
+	case slog.KindFloat64:
+		if f := v.Float64(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+		return v.String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	default:
+		return v.String()
+	}
+}
@@ -0,0 +1,270 @@
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2026, 1, 2, 15, 4, 5, 123456789, time.UTC)
+
+// listenUDP starts a GELF UDP server, that reassembles the chunks
+// and decompresses the messages.
+func listenUDP(t *testing.T) (addr string, read func() map[string]any) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+
+	chunks := map[string][][]byte{}
+
+	return pc.LocalAddr().String(), func() map[string]any {
+		t.Helper()
+
+		buf := make([]byte, 64*1024)
+		for {
+			require.NoError(t, pc.SetReadDeadline(time.Now().Add(time.Second)))
+			n, _, err := pc.ReadFrom(buf)
+			require.NoError(t, err)
+			data := bytes.Clone(buf[:n])
+
+			if !bytes.HasPrefix(data, chunkMagic) {
+				return decode(t, data)
+			}
+
+			id, seq, count := string(data[2:10]), int(data[10]), int(data[11])
+			if chunks[id] == nil {
+				chunks[id] = make([][]byte, count)
+			}
+			chunks[id][seq] = data[chunkHeaderSize:]
+
+			if complete := !containsNil(chunks[id]); complete {
+				msg := bytes.Join(chunks[id], nil)
+				delete(chunks, id)
+				return decode(t, msg)
+			}
+		}
+	}
+}
+
+func containsNil(bs [][]byte) bool {
+	for _, b := range bs {
+		if b == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func decode(t *testing.T, data []byte) map[string]any {
+	t.Helper()
+
+	var r io.Reader = bytes.NewReader(data)
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(r)
+		require.NoError(t, err)
+		r = gz
+	case data[0] == 0x78:
+		zr, err := zlib.NewReader(r)
+		require.NoError(t, err)
+		r = zr
+	}
+
+	var msg map[string]any
+	require.NoError(t, json.NewDecoder(r).Decode(&msg))
+	return msg
+}
+
+func TestHandler_Message(t *testing.T) {
+	addr, read := listenUDP(t)
+
+	h, err := New("udp", addr, Host("host"), WithLevel(slog.LevelDebug))
+	require.NoError(t, err)
+	defer h.Close()
+
+	hh := h.WithAttrs([]slog.Attr{slog.String("service", "api")}).
+		WithGroup("request").
+		WithAttrs([]slog.Attr{slog.String("method", "GET")})
+
+	rec := slog.NewRecord(testTime, slog.LevelError, "failed\nwith details", 0)
+	rec.AddAttrs(
+		slog.Group("headers", slog.String("Content-Type", "text/plain")),
+		slog.Int("status", 500),
+		slog.Float64("ratio", 0.5),
+		slog.Float64("nan", math.NaN()),
+		slog.Bool("retry", true),
+		slog.Duration("took", time.Second),
+		slog.Time("at", testTime),
+		slog.Any("ids", []int{1, 2}),
+		slog.Group("", slog.String("inline", "yes")),
+		slog.String("user name", "john"),
+	)
+	require.NoError(t, hh.Handle(context.Background(), rec))
+
+	assert.Equal(t, map[string]any{
+		"version":                       "1.1",
+		"host":                          "host",
+		"short_message":                 "failed",
+		"full_message":                  "failed\nwith details",
+		"timestamp":                     1767366245.123,
+		"level":                         float64(3),
+		"_service":                      "api",
+		"_request.method":               "GET",
+		"_request.headers.Content-Type": "text/plain",
+		"_request.status":               float64(500),
+		"_request.ratio":                0.5,
+		"_request.nan":                  "NaN",
+		"_request.retry":                "true",
+		"_request.took":                 "1s",
+		"_request.at":                   "2026-01-02T15:04:05.123456789Z",
+		"_request.ids":                  "[1 2]",
+		"_request.inline":               "yes",
+		"_request.user_name":            "john",
+	}, read())
+}
+
+func TestHandler_Options(t *testing.T) {
+	addr, read := listenUDP(t)
+
+	h, err := New("udp", addr, Host("host"), Separator("_"), WithCompression(CompressZlib))
+	require.NoError(t, err)
+	defer h.Close()
+
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+
+	slog.New(h).Log(context.Background(), slogx.LevelNotice, "message",
+		slog.String("id", "reserved"), slog.Group("g", slog.Int("a", 1)))
+
+	msg := read()
+	assert.Equal(t, "message", msg["short_message"])
+	assert.Equal(t, float64(5), msg["level"])
+	assert.Equal(t, "reserved", msg["__id"])
+	assert.Equal(t, float64(1), msg["_g_a"])
+	assert.NotContains(t, msg, "full_message")
+}
+
+func TestHandler_CompressionReused(t *testing.T) {
+	for _, c := range []Compression{CompressGzip, CompressZlib} {
+		addr, read := listenUDP(t)
+
+		h, err := New("udp", addr, WithCompression(c))
+		require.NoError(t, err)
+		defer h.Close()
+
+		lg := slog.New(h)
+		for i := range 3 {
+			lg.Info("message", slog.Int("i", i))
+			assert.Equal(t, float64(i), read()["_i"])
+		}
+		assert.NotNil(t, h.t.(*udpTransport).zw)
+	}
+}
+
+func TestHandler_Chunking(t *testing.T) {
+	addr, read := listenUDP(t)
+
+	h, err := New("udp", addr, Host("host"), WithCompression(CompressNone), ChunkSize(100))
+	require.NoError(t, err)
+	defer h.Close()
+
+	long := strings.Repeat("0123456789", 100)
+	slog.New(h).Info("message", "long", long)
+	assert.Equal(t, long, read()["_long"])
+
+	err = h.Handle(context.Background(), slog.NewRecord(testTime, slog.LevelInfo, strings.Repeat("x", 100*maxChunks), 0))
+	assert.ErrorContains(t, err, "chunks, more than 128")
+
+	_, err = New("udp", addr, ChunkSize(chunkHeaderSize))
+	assert.ErrorContains(t, err, "chunk size must be greater than 12")
+}
+
+func TestHandler_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	msgs := make(chan map[string]any, 10)
+	conns := make(chan net.Conn, 10)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- c
+			go func() {
+				r := bufio.NewReader(c)
+				for {
+					data, err := r.ReadBytes(0)
+					if err != nil {
+						return
+					}
+					var msg map[string]any
+					if err = json.Unmarshal(data[:len(data)-1], &msg); err != nil {
+						return
+					}
+					msgs <- msg
+				}
+			}()
+		}
+	}()
+
+	h, err := New("tcp", ln.Addr().String(), Host("host"))
+	require.NoError(t, err)
+	defer h.Close()
+
+	lg := slog.New(h)
+	lg.Info("first", "k", "v")
+	lg.Info("second")
+
+	first := <-msgs
+	assert.Equal(t, "first", first["short_message"])
+	assert.Equal(t, "v", first["_k"])
+	assert.Equal(t, "second", (<-msgs)["short_message"])
+
+	// the server drops the connection, the handler reconnects
+	(<-conns).Close()
+	require.Eventually(t, func() bool {
+		lg.Info("after reconnect")
+		select {
+		case msg := <-msgs:
+			return msg["short_message"] == "after reconnect"
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, conns, 1, "must reconnect")
+
+	require.NoError(t, h.Close())
+	assert.ErrorIs(t, h.Handle(context.Background(), slog.NewRecord(testTime, slog.LevelInfo, "closed", 0)), net.ErrClosed)
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New("unix", "/dev/log")
+	assert.ErrorContains(t, err, `unsupported network "unix"`)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	_, err = New("tcp", addr)
+	assert.ErrorContains(t, err, "dial "+addr)
+}
@@ -0,0 +1,155 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/cappuccinotm/slogx/internal/netconn"
+)
+
+const (
+	chunkHeaderSize = 12  // magic, message ID, sequence number and count
+	maxChunks       = 128 // maximal amount of chunks, accepted by Graylog
+)
+
+var chunkMagic = []byte{0x1e, 0x0f}
+
+type transport interface {
+	send(msg []byte) error
+	close() error
+}
+
+// udpTransport sends compressed messages over UDP, splitting them into
+// chunks, if they don't fit into a datagram.
+type udpTransport struct {
+	conn        net.Conn
+	compression Compression
+	chunkSize   int
+	timeout     time.Duration
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+	// zw is the compressor, reset for each message
+	zw interface {
+		io.WriteCloser
+		Reset(io.Writer)
+	}
+	// chunk is the buffer for the chunk
+	chunk []byte
+}
+
+func newUDPTransport(network, addr string, o *options) (*udpTransport, error) {
+	if o.chunkSize <= chunkHeaderSize {
+		return nil, fmt.Errorf("chunk size must be greater than %d", chunkHeaderSize)
+	}
+
+	conn, err := net.DialTimeout(network, addr, o.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+
+	return &udpTransport{
+		conn:        conn,
+		compression: o.compression,
+		chunkSize:   o.chunkSize,
+		timeout:     o.timeout,
+		chunk:       make([]byte, o.chunkSize),
+	}, nil
+}
+
+func (t *udpTransport) send(msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := t.compress(msg)
+	if err != nil {
+		return err
+	}
+
+	if t.timeout > 0 {
+		if err = t.conn.SetWriteDeadline(time.Now().Add(t.timeout)); err != nil {
+			return fmt.Errorf("set write deadline: %w", err)
+		}
+	}
+
+	if len(data) <= t.chunkSize {
+		if _, err = t.conn.Write(data); err != nil {
+			return fmt.Errorf("write message: %w", err)
+		}
+		return nil
+	}
+
+	payload := t.chunkSize - chunkHeaderSize
+	count := (len(data) + payload - 1) / payload
+	if count > maxChunks {
+		return fmt.Errorf("message of %d bytes needs %d chunks, more than %d", len(data), count, maxChunks)
+	}
+
+	// magic, message ID, sequence number, count
+	copy(t.chunk, chunkMagic)
+	id := rand.Uint64()
+	for i := range 8 {
+		t.chunk[2+i] = byte(id >> (56 - 8*i))
+	}
+	t.chunk[11] = byte(count)
+
+	for seq := range count {
+		t.chunk[10] = byte(seq)
+		n := copy(t.chunk[chunkHeaderSize:], data[seq*payload:])
+		if _, err = t.conn.Write(t.chunk[:chunkHeaderSize+n]); err != nil {
+			return fmt.Errorf("write chunk %d/%d: %w", seq+1, count, err)
+		}
+	}
+
+	return nil
+}
+
+func (t *udpTransport) compress(msg []byte) ([]byte, error) {
+	t.buf.Reset()
+	switch {
+	case t.zw != nil:
+		t.zw.Reset(&t.buf)
+	case t.compression == CompressGzip:
+		t.zw = gzip.NewWriter(&t.buf)
+	case t.compression == CompressZlib:
+		t.zw = zlib.NewWriter(&t.buf)
+	default:
+		return msg, nil
+	}
+
+	if _, err := t.zw.Write(msg); err != nil {
+		return nil, fmt.Errorf("compress message: %w", err)
+	}
+	if err := t.zw.Close(); err != nil {
+		return nil, fmt.Errorf("compress message: %w", err)
+	}
+
+	return t.buf.Bytes(), nil
+}
+
+func (t *udpTransport) close() error { return t.conn.Close() }
+
+// tcpTransport sends null-delimited messages over TCP,
+// reconnecting on errors.
+type tcpTransport struct{ conn *netconn.Conn }
+
+func newTCPTransport(network, addr string, timeout time.Duration) (*tcpTransport, error) {
+	conn, err := netconn.Dial(network, addr, timeout, func(dst, msg []byte) []byte {
+		return append(append(dst, msg...), 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tcpTransport{conn: conn}, nil
+}
+
+func (t *tcpTransport) send(msg []byte) error { return t.conn.Write(msg) }
+
+func (t *tcpTransport) close() error { return t.conn.Close() }
@@ -0,0 +1,71 @@
+// Package grouped keeps the attributes of the handler along with the groups
+// they were added in, shared by the handlers, that flatten the groups
+// into the names of the fields.
+package grouped
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// Attrs is the state of the handler, built with WithAttrs and WithGroup.
+// The zero value has no attributes and groups.
+type Attrs struct {
+	groups []string
+	attrs  []attr
+}
+
+type attr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+// WithAttrs returns Attrs with the attributes added in the current groups.
+func (a Attrs) WithAttrs(attrs []slog.Attr) Attrs {
+	res := Attrs{groups: a.groups, attrs: slices.Clip(a.attrs)}
+	for _, at := range attrs {
+		res.attrs = append(res.attrs, attr{groups: a.groups, attr: at})
+	}
+	return res
+}
+
+// WithGroup returns Attrs with the group opened, empty name is ignored.
+func (a Attrs) WithGroup(name string) Attrs {
+	if name == "" {
+		return a
+	}
+	return Attrs{groups: append(slices.Clip(a.groups), name), attrs: a.attrs}
+}
+
+// Walk calls fn for each attribute of the handler and of the record,
+// the values are resolved, groups are flattened into the path of their
+// keys (groups with empty keys are inlined) and empty attributes are skipped.
+func (a Attrs) Walk(rec slog.Record, fn func(groups []string, a slog.Attr)) {
+	for _, ga := range a.attrs {
+		walk(ga.groups, ga.attr, fn)
+	}
+	rec.Attrs(func(at slog.Attr) bool {
+		walk(a.groups, at, fn)
+		return true
+	})
+}
+
+func walk(groups []string, a slog.Attr, fn func(groups []string, a slog.Attr)) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range a.Value.Group() {
+			walk(groups, ga, fn)
+		}
+		return
+	}
+
+	if a.Key == "" && a.Value.Kind() == slog.KindAny && a.Value.Any() == nil { // empty attribute
+		return
+	}
+
+	fn(groups, a)
+}
@@ -0,0 +1,39 @@
+package grouped
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttrs_Walk(t *testing.T) {
+	a := Attrs{}.
+		WithAttrs([]slog.Attr{slog.String("service", "api")}).
+		WithGroup("").
+		WithGroup("request").
+		WithAttrs([]slog.Attr{slog.String("method", "GET")})
+
+	// derived Attrs must not share the appended attributes
+	_ = a.WithAttrs([]slog.Attr{slog.String("other", "value")})
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "message", 0)
+	rec.AddAttrs(
+		slog.Group("headers", slog.String("Accept", "*/*")),
+		slog.Group("", slog.Int("inline", 1)),
+		slog.Attr{},
+	)
+
+	var res []string
+	a.Walk(rec, func(groups []string, a slog.Attr) {
+		res = append(res, strings.Join(append(groups, a.Key), ".")+"="+a.Value.String())
+	})
+	assert.Equal(t, []string{
+		"service=api",
+		"request.method=GET",
+		"request.headers.Accept=*/*",
+		"request.inline=1",
+	}, res)
+}
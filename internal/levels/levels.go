@@ -0,0 +1,97 @@
+// Package levels keeps the names of the log levels, shared by slogx
+// and its subpackages, which can't import slogx itself.
+package levels
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Levels, defined in addition to the standard slog ones.
+const (
+	Trace    slog.Level = -8
+	Notice   slog.Level = 2
+	Critical slog.Level = 12
+	Fatal    slog.Level = 16
+)
+
+type named struct {
+	lvl  slog.Level
+	name string
+}
+
+var (
+	mu    sync.RWMutex
+	names = []named{ // sorted by level
+		{Trace, "TRACE"},
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo, "INFO"},
+		{Notice, "NOTICE"},
+		{slog.LevelWarn, "WARN"},
+		{slog.LevelError, "ERROR"},
+		{Critical, "CRITICAL"},
+		{Fatal, "FATAL"},
+	}
+)
+
+// Register sets the name of the level, replacing the existing one.
+func Register(lvl slog.Level, name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	i, found := slices.BinarySearchFunc(names, lvl, func(n named, lvl slog.Level) int { return int(n.lvl - lvl) })
+	if found {
+		names[i].name = name
+		return
+	}
+	names = slices.Insert(names, i, named{lvl: lvl, name: name})
+}
+
+// Name returns the name of the level. Levels without names are named
+// after the closest named level below them with the offset, like slog
+// does, e.g. "INFO+1", or after the lowest named level, if there are
+// no levels below, e.g. "TRACE-2".
+func Name(lvl slog.Level) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	i, found := slices.BinarySearchFunc(names, lvl, func(n named, lvl slog.Level) int { return int(n.lvl - lvl) })
+	switch {
+	case found:
+		return names[i].name
+	case len(names) == 0:
+		return lvl.String()
+	case i == 0:
+		return fmt.Sprintf("%s%+d", names[0].name, lvl-names[0].lvl)
+	default:
+		return fmt.Sprintf("%s%+d", names[i-1].name, lvl-names[i-1].lvl)
+	}
+}
+
+// Parse parses the level from its name, case-insensitively, with an
+// optional offset, e.g. "notice", "INFO+1" or "trace-2".
+func Parse(s string) (slog.Level, error) {
+	name, offset := s, 0
+	if i := strings.IndexAny(s, "+-"); i > 0 {
+		var err error
+		if offset, err = strconv.Atoi(s[i:]); err != nil {
+			return 0, fmt.Errorf("parse level offset %q: %w", s, err)
+		}
+		name = s[:i]
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, n := range names {
+		if strings.EqualFold(n.name, name) {
+			return n.lvl + slog.Level(offset), nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown level name %q", s)
+}
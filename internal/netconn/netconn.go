@@ -0,0 +1,111 @@
+// Package netconn provides the connection to the log server, that is
+// redialed on errors, shared by the network handlers.
+package netconn
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// FrameFunc appends the framed message to dst, e.g. with its length
+// as the prefix or the delimiter as the suffix.
+type FrameFunc func(dst, msg []byte) []byte
+
+// Conn is a connection to the server, that is redialed on errors.
+type Conn struct {
+	network, addr string
+	timeout       time.Duration
+	frame         FrameFunc // nil, if messages are written as is
+
+	mu     sync.Mutex
+	c      net.Conn
+	closed bool
+	// buf is the buffer for the framed messages
+	buf []byte
+}
+
+// Dial connects to the server. Each message is framed with frame,
+// if it is not nil, timeout limits dialing and each write.
+func Dial(network, addr string, timeout time.Duration, frame FrameFunc) (*Conn, error) {
+	c := &Conn{network: network, addr: addr, timeout: timeout, frame: frame}
+	if err := c.dial(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Write sends the message to the server, redialing once, if the connection
+// is broken.
+func (c *Conn) Write(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return net.ErrClosed
+	}
+
+	if c.frame != nil {
+		c.buf = c.frame(c.buf[:0], msg)
+		msg = c.buf
+	}
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if c.c == nil {
+			if err = c.dial(); err != nil {
+				continue
+			}
+		}
+
+		if err = c.send(msg); err == nil {
+			return nil
+		}
+
+		_ = c.c.Close()
+		c.c = nil
+	}
+
+	return err
+}
+
+// Close closes the connection, the following writes fail with net.ErrClosed.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	if c.c == nil {
+		return nil
+	}
+
+	err := c.c.Close()
+	c.c = nil
+	return err
+}
+
+func (c *Conn) send(msg []byte) error {
+	if c.timeout > 0 {
+		if err := c.c.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+			return fmt.Errorf("set write deadline: %w", err)
+		}
+	}
+
+	if _, err := c.c.Write(msg); err != nil {
+		return fmt.Errorf("write to %s: %w", c.addr, err)
+	}
+
+	return nil
+}
+
+// dial connects to the server. Must be called under the lock,
+// if the connection is in use.
+func (c *Conn) dial() error {
+	nc, err := net.DialTimeout(c.network, c.addr, c.timeout)
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", c.addr, err)
+	}
+	c.c = nc
+	return nil
+}
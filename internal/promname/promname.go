@@ -0,0 +1,17 @@
+// Package promname provides the helpers for the names of the labels in
+// the Prometheus data model, shared by the loki handler and the metrics
+// middleware.
+package promname
+
+// Sanitize replaces the characters, that are not allowed in the names
+// of labels and metrics, with underscores.
+func Sanitize(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		isLetter := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+		if !isLetter && (i == 0 || c < '0' || c > '9') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,175 @@
+package slogx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// levelStep is the distance between the standard slog levels.
+const levelStep = 4
+
+// LevelRegistry is a set of named levels, e.g. one per subsystem logger,
+// which can be changed at runtime.
+// Levels are slog.LevelVar's, so they can be passed to any handler that
+// accepts slog.Leveler, e.g. to Chain.WithLevel or fblog.WithLevel.
+type LevelRegistry struct {
+	def slog.Level
+
+	mu     sync.Mutex
+	levels map[string]*registeredLevel
+}
+
+type registeredLevel struct {
+	v *slog.LevelVar
+
+	// for temporary changes
+	revert *time.Timer
+	orig   slog.Level
+}
+
+// NewLevelRegistry makes a new LevelRegistry, in which levels are
+// created with the given default level.
+func NewLevelRegistry(def slog.Level) *LevelRegistry {
+	return &LevelRegistry{def: def, levels: map[string]*registeredLevel{}}
+}
+
+// Level returns the level with the given name, creating it if it doesn't exist.
+func (r *LevelRegistry) Level(name string) *slog.LevelVar {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.get(name).v
+}
+
+// Set sets the level with the given name, creating it if it doesn't exist.
+// If ttl is positive, the level is reverted to the value it had before
+// the first temporary change, after ttl passes.
+func (r *LevelRegistry) Set(name string, lvl slog.Level, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l := r.get(name)
+
+	if l.revert != nil {
+		l.revert.Stop()
+		l.revert = nil
+	} else {
+		l.orig = l.v.Level()
+	}
+
+	l.v.Set(lvl)
+
+	if ttl <= 0 {
+		return
+	}
+
+	var t *time.Timer
+	t = time.AfterFunc(ttl, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if l.revert != t { // level has been changed since then
+			return
+		}
+		l.v.Set(l.orig)
+		l.revert = nil
+	})
+	l.revert = t
+}
+
+// Levels returns the current values of all the levels.
+func (r *LevelRegistry) Levels() map[string]slog.Level {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make(map[string]slog.Level, len(r.levels))
+	for name, l := range r.levels {
+		res[name] = l.v.Level()
+	}
+	return res
+}
+
+// Step shifts all the levels by delta, e.g. a step of -4 makes
+// INFO levels DEBUG ones. Pending reverts are canceled.
+func (r *LevelRegistry) Step(delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, l := range r.levels {
+		if l.revert != nil {
+			l.revert.Stop()
+			l.revert = nil
+		}
+		l.v.Set(l.v.Level() + slog.Level(delta))
+	}
+}
+
+// ServeHTTP lists the levels on GET requests and sets the level on
+// POST and PUT requests, with the parameters "name", "level" and optional "ttl",
+// passed either in the query or in the form, e.g.:
+//
+//	curl -X POST 'localhost:8080/debug/levels?name=db&level=debug&ttl=5m'
+//
+// Levels are parsed with ParseLevel and listed with LevelName, so the levels,
+// defined by slogx, are understood, e.g. "trace" and "notice".
+// Both cases respond with the JSON object of level names to their values.
+func (r *LevelRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		if err := r.set(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	lvls := r.Levels()
+	resp := make(map[string]string, len(lvls))
+	for name, lvl := range lvls {
+		resp[name] = LevelName(lvl)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (r *LevelRegistry) set(req *http.Request) error {
+	name := req.FormValue("name")
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	lvl, err := ParseLevel(req.FormValue("level"))
+	if err != nil {
+		return fmt.Errorf("parse level: %w", err)
+	}
+
+	var ttl time.Duration
+	if s := req.FormValue("ttl"); s != "" {
+		if ttl, err = time.ParseDuration(s); err != nil {
+			return fmt.Errorf("parse ttl: %w", err)
+		}
+	}
+
+	r.Set(name, lvl, ttl)
+	return nil
+}
+
+// get returns the level with the given name, creating it if needed.
+// Must be called under the lock.
+func (r *LevelRegistry) get(name string) *registeredLevel {
+	l, ok := r.levels[name]
+	if !ok {
+		l = &registeredLevel{v: &slog.LevelVar{}}
+		l.v.Set(r.def)
+		r.levels[name] = l
+	}
+	return l
+}
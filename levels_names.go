@@ -0,0 +1,46 @@
+package slogx
+
+import (
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx/internal/levels"
+)
+
+// Levels, defined in addition to the standard slog ones.
+// Their names are understood by fblog, slogt and ReplaceLevelName.
+const (
+	LevelTrace    = levels.Trace    // -8, below DEBUG
+	LevelNotice   = levels.Notice   // 2, between INFO and WARN
+	LevelCritical = levels.Critical // 12, above ERROR
+	LevelFatal    = levels.Fatal    // 16, used by Logger.Fatal
+)
+
+// RegisterLevelName sets the name of the level, replacing the existing one,
+// e.g. to add a custom level or to rename one of the predefined ones.
+// It should be called before any logging, e.g. in init.
+func RegisterLevelName(lvl slog.Level, name string) { levels.Register(lvl, name) }
+
+// LevelName returns the registered name of the level. Levels without names
+// are named after the closest named level below them, like slog does,
+// e.g. "NOTICE+1".
+func LevelName(lvl slog.Level) string { return levels.Name(lvl) }
+
+// ParseLevel parses the level from its registered name, case-insensitively,
+// with an optional offset, e.g. "trace", "NOTICE" or "INFO+1".
+func ParseLevel(s string) (slog.Level, error) { return levels.Parse(s) }
+
+// ReplaceLevelName is a function for slog.HandlerOptions.ReplaceAttr, that
+// renders the level of the record with its registered name, e.g.:
+//
+//	slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: slogx.ReplaceLevelName})
+func ReplaceLevelName(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) > 0 || attr.Key != slog.LevelKey {
+		return attr
+	}
+
+	if lvl, ok := attr.Value.Any().(slog.Level); ok {
+		return slog.String(attr.Key, LevelName(lvl))
+	}
+
+	return attr
+}
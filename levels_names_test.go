@@ -0,0 +1,75 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelName(t *testing.T) {
+	for lvl, name := range map[slog.Level]string{
+		LevelTrace - 2:      "TRACE-2",
+		LevelTrace:          "TRACE",
+		slog.LevelDebug:     "DEBUG",
+		slog.LevelDebug + 1: "DEBUG+1",
+		slog.LevelInfo:      "INFO",
+		LevelNotice:         "NOTICE",
+		LevelNotice + 1:     "NOTICE+1",
+		slog.LevelWarn:      "WARN",
+		slog.LevelError:     "ERROR",
+		LevelCritical:       "CRITICAL",
+		LevelFatal:          "FATAL",
+		LevelFatal + 1:      "FATAL+1",
+	} {
+		assert.Equal(t, name, LevelName(lvl), "level %d", lvl)
+	}
+
+	RegisterLevelName(20, "PANIC")
+	assert.Equal(t, "PANIC", LevelName(20))
+	assert.Equal(t, "PANIC+1", LevelName(21))
+	assert.Equal(t, "FATAL+3", LevelName(19))
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, lvl := range map[string]slog.Level{
+		"trace":    LevelTrace,
+		"Notice":   LevelNotice,
+		"INFO+1":   slog.LevelInfo + 1,
+		"trace-2":  LevelTrace - 2,
+		"critical": LevelCritical,
+		"fatal":    LevelFatal,
+	} {
+		got, err := ParseLevel(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, lvl, got, s)
+	}
+
+	_, err := ParseLevel("loud")
+	assert.ErrorContains(t, err, `unknown level name "loud"`)
+
+	_, err = ParseLevel("info+x")
+	assert.ErrorContains(t, err, "parse level offset")
+}
+
+func TestReplaceLevelName(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lg := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		Level: LevelTrace,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return ReplaceLevelName(groups, a)
+		},
+	}))
+
+	lg.Log(context.Background(), LevelTrace, "trace", slog.Group("g", slog.String("level", "kept")))
+	lg.Log(context.Background(), LevelNotice, "notice")
+
+	assert.Equal(t, `{"level":"TRACE","msg":"trace","g":{"level":"kept"}}`+"\n"+
+		`{"level":"NOTICE","msg":"notice"}`+"\n", buf.String())
+}
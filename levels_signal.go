@@ -0,0 +1,38 @@
+//go:build !windows
+
+package slogx
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// HandleSignals makes all the levels in the registry more verbose
+// by one step (e.g. INFO -> DEBUG) on SIGUSR1 and less verbose on SIGUSR2.
+// Returned function stops handling the signals.
+func (r *LevelRegistry) HandleSignals() (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				if sig == syscall.SIGUSR1 {
+					r.Step(-levelStep)
+					continue
+				}
+				r.Step(levelStep)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package slogx
+
+import (
+	"log/slog"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelRegistry_HandleSignals(t *testing.T) {
+	r := NewLevelRegistry(slog.LevelInfo)
+	lvl := r.Level("db")
+
+	stop := r.HandleSignals()
+	defer stop()
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return lvl.Level() == slog.LevelDebug }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	require.Eventually(t, func() bool { return lvl.Level() == slog.LevelInfo }, time.Second, 5*time.Millisecond)
+}
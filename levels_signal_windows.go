@@ -0,0 +1,4 @@
+package slogx
+
+// HandleSignals does nothing on Windows, as there are no SIGUSR1 and SIGUSR2 signals.
+func (r *LevelRegistry) HandleSignals() (stop func()) { return func() {} }
@@ -0,0 +1,123 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelRegistry_Set(t *testing.T) {
+	t.Run("permanent", func(t *testing.T) {
+		r := NewLevelRegistry(slog.LevelInfo)
+		lvl := r.Level("db")
+		assert.Equal(t, slog.LevelInfo, lvl.Level())
+
+		r.Set("db", slog.LevelDebug, 0)
+		assert.Equal(t, slog.LevelDebug, lvl.Level())
+		assert.Equal(t, map[string]slog.Level{"db": slog.LevelDebug}, r.Levels())
+	})
+
+	t.Run("temporary", func(t *testing.T) {
+		r := NewLevelRegistry(slog.LevelInfo)
+		r.Set("db", slog.LevelWarn, 0)
+		r.Set("db", slog.LevelDebug, time.Hour)
+		r.Set("db", slog.LevelError, 10*time.Millisecond) // must revert to the value before the first change
+		assert.Equal(t, slog.LevelError, r.Level("db").Level())
+
+		require.Eventually(t, func() bool { return r.Level("db").Level() == slog.LevelWarn },
+			time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("temporary canceled by permanent", func(t *testing.T) {
+		r := NewLevelRegistry(slog.LevelInfo)
+		r.Set("db", slog.LevelDebug, 10*time.Millisecond)
+		r.Set("db", slog.LevelError, 0)
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, slog.LevelError, r.Level("db").Level())
+	})
+}
+
+func TestLevelRegistry_Step(t *testing.T) {
+	r := NewLevelRegistry(slog.LevelInfo)
+	r.Set("db", slog.LevelWarn, 0)
+	r.Level("http")
+
+	r.Step(-levelStep)
+	assert.Equal(t, map[string]slog.Level{"db": slog.LevelInfo, "http": slog.LevelDebug}, r.Levels())
+}
+
+func TestLevelRegistry_ServeHTTP(t *testing.T) {
+	r := NewLevelRegistry(slog.LevelInfo)
+	r.Level("db")
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	decode := func(t *testing.T, resp *http.Response) map[string]string {
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		var res map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&res))
+		return res
+	}
+
+	resp, err := http.Get(ts.URL)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db": "INFO"}, decode(t, resp))
+
+	resp, err = http.Post(ts.URL+"?name=http&level=debug", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db": "INFO", "http": "DEBUG"}, decode(t, resp))
+
+	resp, err = http.Post(ts.URL+"?name=db&level=error&ttl=10ms", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db": "ERROR", "http": "DEBUG"}, decode(t, resp))
+	require.Eventually(t, func() bool { return r.Level("db").Level() == slog.LevelInfo },
+		time.Second, 5*time.Millisecond)
+
+	resp, err = http.Post(ts.URL+"?name=db&level=notice", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db": "NOTICE", "http": "DEBUG"}, decode(t, resp))
+
+	resp, err = http.Post(ts.URL+"?name=http&level=trace", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"db": "NOTICE", "http": "TRACE"}, decode(t, resp))
+	assert.Equal(t, LevelTrace, r.Level("http").Level())
+
+	for _, q := range []string{"?level=debug", "?name=db&level=bad", "?name=db&level=debug&ttl=bad"} {
+		resp, err = http.Post(ts.URL+q, "", nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, q)
+		resp.Body.Close()
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, ts.URL, http.NoBody)
+	require.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestChain_WithLevel(t *testing.T) {
+	r := NewLevelRegistry(slog.LevelWarn)
+	buf := &bytes.Buffer{}
+	h := NewChain(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})).
+		WithLevel(r.Level("db"))
+
+	lg := slog.New(h).With(slog.String("a", "1"))
+	lg.Info("first")
+	assert.Empty(t, buf.String())
+
+	r.Set("db", slog.LevelInfo, 0)
+	lg.Info("second")
+	assert.Contains(t, buf.String(), "second")
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+}
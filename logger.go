@@ -0,0 +1,146 @@
+package slogx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Exit is called by Logger.Fatal to terminate the program, after the record
+// is logged and the handler is flushed. It may be replaced, e.g. in tests.
+var Exit = os.Exit
+
+// fatalFlushTimeout limits the time Logger.Fatal waits for the handler
+// to be flushed.
+const fatalFlushTimeout = 5 * time.Second
+
+// Logger is a slog.Logger with the methods for the levels, defined
+// by slogx, e.g. Trace and Fatal.
+type Logger struct {
+	*slog.Logger
+}
+
+// NewLogger makes a new Logger with the given handler.
+func NewLogger(h slog.Handler) *Logger { return &Logger{Logger: slog.New(h)} }
+
+// With returns a Logger that includes the given attributes in each output.
+func (l *Logger) With(args ...any) *Logger { return &Logger{Logger: l.Logger.With(args...)} }
+
+// WithGroup returns a Logger that starts a group with the given name.
+func (l *Logger) WithGroup(name string) *Logger { return &Logger{Logger: l.Logger.WithGroup(name)} }
+
+// Trace logs at LevelTrace.
+func (l *Logger) Trace(msg string, args ...any) { l.log(context.Background(), LevelTrace, msg, args) }
+
+// TraceContext logs at LevelTrace with the given context.
+func (l *Logger) TraceContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, LevelTrace, msg, args)
+}
+
+// Notice logs at LevelNotice.
+func (l *Logger) Notice(msg string, args ...any) { l.log(context.Background(), LevelNotice, msg, args) }
+
+// NoticeContext logs at LevelNotice with the given context.
+func (l *Logger) NoticeContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, LevelNotice, msg, args)
+}
+
+// Critical logs at LevelCritical.
+func (l *Logger) Critical(msg string, args ...any) {
+	l.log(context.Background(), LevelCritical, msg, args)
+}
+
+// CriticalContext logs at LevelCritical with the given context.
+func (l *Logger) CriticalContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, LevelCritical, msg, args)
+}
+
+// Fatal logs at LevelFatal, flushes the handler and calls Exit(1).
+// See FatalContext for details.
+func (l *Logger) Fatal(msg string, args ...any) {
+	l.log(context.Background(), LevelFatal, msg, args)
+	l.exit(context.Background())
+}
+
+// FatalContext logs at LevelFatal with the given context, flushes the handler
+// and calls Exit(1). The handler is flushed, if it has any of the methods
+// Flush(context.Context) error, Flush() error, Close(context.Context) error
+// or Close() error, e.g. AsyncHandler, the first one found is called.
+func (l *Logger) FatalContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, LevelFatal, msg, args)
+	l.exit(ctx)
+}
+
+func (l *Logger) log(ctx context.Context, lvl slog.Level, msg string, args []any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	h := l.Handler()
+	if !h.Enabled(ctx, lvl) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:]) // skip [runtime.Callers, log, caller]
+	rec := slog.NewRecord(time.Now(), lvl, msg, pcs[0])
+	rec.Add(args...)
+	_ = h.Handle(ctx, rec)
+}
+
+func (l *Logger) exit(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fatalFlushTimeout)
+	defer cancel()
+
+	flushHandler(ctx, l.Handler())
+
+	Exit(1)
+}
+
+// flushHandler flushes or closes the handler, if it has Flush or Close method.
+// Chains, Fanout (with its branches), Routers, Accumulator and
+// BacktraceHandler are unwrapped to reach the handlers they pass
+// the records to.
+func flushHandler(ctx context.Context, h slog.Handler) {
+	switch h := h.(type) {
+	case *Chain:
+		for _, mw := range h.mws {
+			if ri, ok := mw.(routerInterceptor); ok {
+				flushHandler(ctx, ri.r)
+			}
+		}
+		flushHandler(ctx, h.Handler)
+	case *fanout:
+		for _, fh := range h.hs {
+			flushHandler(ctx, fh)
+		}
+	case *leveled:
+		flushHandler(ctx, h.Handler)
+	case *accumulator:
+		flushHandler(ctx, h.Handler)
+	case *BacktraceHandler:
+		flushHandler(ctx, h.h)
+	case *Router:
+		for _, rule := range h.rules {
+			flushHandler(ctx, rule.Handler)
+		}
+		if h.fallback != nil {
+			flushHandler(ctx, h.fallback)
+		}
+	case interface{ Flush(context.Context) error }:
+		_ = h.Flush(ctx)
+	case interface{ Flush() error }:
+		_ = h.Flush()
+	case interface{ Close(context.Context) error }:
+		_ = h.Close(ctx)
+	case io.Closer:
+		_ = h.Close()
+	}
+}
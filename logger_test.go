@@ -0,0 +1,133 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"testing"
+
+	"github.com/cappuccinotm/slogx/slogt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger(t *testing.T) {
+	var recs []slog.Record
+	lg := NewLogger(slogt.HandlerFunc(func(_ context.Context, rec slog.Record) error {
+		recs = append(recs, rec)
+		return nil
+	})).With("k", "v")
+
+	lg.Trace("trace", "a", 1)
+	lg.NoticeContext(context.Background(), "notice")
+	lg.Critical("critical")
+	lg.Info("info")
+
+	require.Len(t, recs, 4)
+	assert.Equal(t, LevelTrace, recs[0].Level)
+	assert.Equal(t, "trace", recs[0].Message)
+	assert.Equal(t, []slog.Attr{slog.Int("a", 1)}, Attrs(recs[0]))
+	assert.Equal(t, LevelNotice, recs[1].Level)
+	assert.Equal(t, LevelCritical, recs[2].Level)
+	assert.Equal(t, slog.LevelInfo, recs[3].Level)
+
+	f, _ := runtime.CallersFrames([]uintptr{recs[0].PC}).Next()
+	assert.Equal(t, "github.com/cappuccinotm/slogx.TestLogger", f.Function)
+}
+
+type flushingHandler struct {
+	slogt.HandlerFunc
+	flushed bool
+}
+
+func (h *flushingHandler) Flush(context.Context) error { h.flushed = true; return nil }
+
+type closingHandler struct {
+	slogt.HandlerFunc
+	closed bool
+}
+
+func (h *closingHandler) Close() error { h.closed = true; return nil }
+
+func TestLogger_Fatal(t *testing.T) {
+	var code int
+	orig := Exit
+	Exit = func(c int) { code = c }
+	t.Cleanup(func() { Exit = orig })
+
+	var recs []slog.Record
+	hf := slogt.HandlerFunc(func(_ context.Context, rec slog.Record) error {
+		recs = append(recs, rec)
+		return nil
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		recs, code = nil, 0
+		h := &flushingHandler{HandlerFunc: hf}
+		NewLogger(h).Fatal("fatal", "a", 1)
+		require.Len(t, recs, 1)
+		assert.Equal(t, LevelFatal, recs[0].Level)
+		assert.True(t, h.flushed)
+		assert.Equal(t, 1, code)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		recs, code = nil, 0
+		h := &closingHandler{HandlerFunc: hf}
+		NewLogger(h).FatalContext(context.Background(), "fatal")
+		require.Len(t, recs, 1)
+		assert.True(t, h.closed)
+		assert.Equal(t, 1, code)
+	})
+}
+
+func TestLogger_FatalWrapped(t *testing.T) {
+	orig := Exit
+	Exit = func(int) {}
+	t.Cleanup(func() { Exit = orig })
+
+	var recs []slog.Record
+	hf := slogt.HandlerFunc(func(_ context.Context, rec slog.Record) error {
+		recs = append(recs, rec)
+		return nil
+	})
+
+	t.Run("chain of async", func(t *testing.T) {
+		recs = nil
+		a := Async(hf)
+		t.Cleanup(func() { _ = a.Close(context.Background()) })
+
+		NewLogger(NewChain(a)).With("k", "v").Fatal("fatal")
+		require.Len(t, recs, 1)
+		assert.Equal(t, LevelFatal, recs[0].Level)
+	})
+
+	for name, wrap := range map[string]func(slog.Handler) slog.Handler{
+		"accumulator of async": Accumulator,
+		"backtrace of async":   func(h slog.Handler) slog.Handler { return Backtrace(h) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			recs = nil
+			a := Async(hf)
+			t.Cleanup(func() { _ = a.Close(context.Background()) })
+
+			NewLogger(wrap(a)).Fatal("fatal")
+			require.Len(t, recs, 1)
+			assert.Equal(t, "fatal", recs[0].Message)
+		})
+	}
+
+	t.Run("fanout, branch and router", func(t *testing.T) {
+		closing, flushing := &closingHandler{HandlerFunc: hf}, &flushingHandler{HandlerFunc: hf}
+		fallback := &closingHandler{HandlerFunc: hf}
+		lg := NewLogger(Fanout(
+			Branch(NewChain(closing), slog.LevelInfo),
+			NewRouter(Route(HasAttr("audit"), flushing), RouteFallback(fallback)),
+		))
+
+		lg.Fatal("fatal")
+		assert.True(t, closing.closed)
+		assert.True(t, flushing.flushed)
+		assert.True(t, fallback.closed)
+	})
+}
@@ -0,0 +1,308 @@
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// client batches the entries and pushes them to Loki.
+type client struct {
+	url  string
+	opts *options
+
+	encMu sync.Mutex // guards line
+	line  bytes.Buffer
+
+	mu      sync.Mutex // guards batch, order, size, dropped and closed
+	batch   map[string]*stream
+	order   []*stream // streams of the entries, oldest first
+	size    int
+	dropped int // entries, dropped since the last flush
+	closed  bool
+	pushMu  sync.Mutex // serializes the pushes
+	full    chan struct{}
+	quit    chan struct{}
+	stopped chan struct{}
+}
+
+// stream is the stream of the push request.
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"` // timestamp in nanoseconds and line
+	key    string
+}
+
+func newClient(url string, o *options) *client {
+	c := &client{
+		url:     url,
+		opts:    o,
+		batch:   map[string]*stream{},
+		full:    make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// encode encodes the line of the record with the encoder.
+func (c *client) encode(ctx context.Context, enc slog.Handler, rec slog.Record) (string, error) {
+	c.encMu.Lock()
+	defer c.encMu.Unlock()
+
+	c.line.Reset()
+	if err := enc.Handle(ctx, rec); err != nil {
+		return "", fmt.Errorf("encode line: %w", err)
+	}
+
+	return strings.TrimSuffix(c.line.String(), "\n"), nil
+}
+
+// add adds the entry to the batch.
+func (c *client) add(labels map[string]string, ts time.Time, line string) error {
+	key := streamKey(labels)
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return slogx.ErrClosed
+	}
+
+	s, ok := c.batch[key]
+	if !ok {
+		s = &stream{Stream: labels, key: key}
+		c.batch[key] = s
+	}
+	s.Values = append(s.Values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), line})
+	c.order = append(c.order, s)
+
+	c.size += len(line)
+	for c.size > c.opts.maxPending && len(c.order) > 0 {
+		c.dropOldest()
+	}
+
+	if c.size >= c.opts.batchSize {
+		select {
+		case c.full <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// dropOldest drops the oldest entry of the batch. Must be called under the lock.
+func (c *client) dropOldest() {
+	s := c.order[0]
+	c.order[0] = nil
+	c.order = c.order[1:]
+
+	c.size -= len(s.Values[0][1])
+	s.Values[0] = [2]string{}
+	s.Values = s.Values[1:]
+	if len(s.Values) == 0 {
+		delete(c.batch, s.key)
+	}
+
+	c.dropped++
+}
+
+// streamKey returns the key of the stream with the labels.
+func streamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(strconv.Quote(labels[k]))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
+
+// run pushes the batches in the background, when they are full
+// or when their time passes.
+func (c *client) run() {
+	defer close(c.stopped)
+
+	ticker := time.NewTicker(c.opts.batchWait)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-c.full:
+		case <-c.quit:
+			return
+		}
+
+		if err := c.flush(context.Background()); err != nil {
+			c.opts.onError(err)
+		}
+	}
+}
+
+// flush pushes the current batch.
+func (c *client) flush(ctx context.Context) error {
+	c.pushMu.Lock()
+	defer c.pushMu.Unlock()
+
+	c.mu.Lock()
+	batch, dropped := c.batch, c.dropped
+	c.batch, c.order, c.size, c.dropped = map[string]*stream{}, nil, 0, 0
+	c.mu.Unlock()
+
+	if dropped > 0 {
+		c.opts.onError(fmt.Errorf("%d entries dropped, pending entries exceeded %d bytes", dropped, c.opts.maxPending))
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := encodeBatch(batch)
+	if err != nil {
+		return err
+	}
+
+	return c.push(ctx, body)
+}
+
+func (c *client) close(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.quit)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return c.flush(ctx)
+}
+
+// encodeBatch encodes the gzipped body of the push request.
+func encodeBatch(batch map[string]*stream) ([]byte, error) {
+	req := struct {
+		Streams []*stream `json:"streams"`
+	}{Streams: make([]*stream, 0, len(batch))}
+	for _, s := range batch {
+		req.Streams = append(req.Streams, s)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(req); err != nil {
+		return nil, fmt.Errorf("encode batch: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress batch: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// push sends the body to Loki, retrying on network errors, 429 and 5xx
+// statuses.
+func (c *client) push(ctx context.Context, body []byte) error {
+	delay := c.opts.minBackoff
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.send(ctx, body)
+		if err == nil {
+			return nil
+		}
+
+		var perr *permanentError
+		if errors.As(err, &perr) {
+			return fmt.Errorf("push batch: %w", err)
+		}
+
+		if attempt >= c.opts.maxRetries {
+			return fmt.Errorf("push batch, %d attempts failed: %w", attempt+1, err)
+		}
+
+		wait := delay
+		if retryAfter > 0 && retryAfter <= c.opts.maxBackoff {
+			wait = retryAfter
+		}
+		delay = min(delay*2, c.opts.maxBackoff)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("push batch: %w", errors.Join(err, ctx.Err()))
+		}
+	}
+}
+
+// permanentError is the error of the push, that must not be retried.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// send sends the body once and returns the delay, requested by the server
+// in the Retry-After header, if any.
+func (c *client) send(ctx context.Context, body []byte) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, &permanentError{err: fmt.Errorf("make request: %w", err)}
+	}
+
+	for k, vs := range c.opts.header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := c.opts.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return 0, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+		return 0, &permanentError{err: err}
+	}
+
+	if secs, perr := strconv.Atoi(resp.Header.Get("Retry-After")); perr == nil && secs > 0 {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+
+	return retryAfter, err
+}
@@ -0,0 +1,286 @@
+// Package loki provides a slog.Handler, that pushes records to Grafana Loki
+// through its push API (/loki/api/v1/push).
+//
+// Records are grouped into streams by their labels: the level of the record,
+// the static labels and the values of the label attributes ("service"
+// by default). The rest of the record, i.e. the message and the other
+// attributes, is the line of the entry, encoded as JSON or logfmt.
+// Entries are pushed in gzipped batches, once the batch exceeds its size
+// or its time, failed pushes are retried with backoff.
+package loki
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/internal/promname"
+)
+
+// Format is the format of the lines.
+type Format uint8
+
+// Supported formats.
+const (
+	FormatJSON   Format = iota // {"msg":"message","key":"value"}
+	FormatLogfmt               // msg=message key=value
+)
+
+type options struct {
+	lvl          slog.Leveler
+	labelKeys    []string
+	staticLabels map[string]string
+	format       Format
+	addSource    bool
+
+	batchSize  int
+	batchWait  time.Duration
+	maxPending int
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	client  *http.Client
+	header  http.Header
+	onError func(error)
+}
+
+// Option is a functional option for New.
+type Option func(*options)
+
+// WithLevel sets the minimum level of the records to push.
+// Default is slog.LevelInfo.
+func WithLevel(lvl slog.Leveler) Option { return func(o *options) { o.lvl = lvl } }
+
+// LabelKeys sets the keys of the top-level attributes, which become the
+// labels of the stream instead of being written to the line.
+// Default is "service". Attributes inside groups never become labels.
+func LabelKeys(keys ...string) Option { return func(o *options) { o.labelKeys = keys } }
+
+// StaticLabels sets the labels, added to all the streams, e.g. the name
+// of the host or the environment. The characters, that are not allowed
+// in the names of labels, are replaced with underscores.
+func StaticLabels(labels map[string]string) Option {
+	return func(o *options) { o.staticLabels = labels }
+}
+
+// WithFormat sets the format of the lines. Default is FormatJSON.
+func WithFormat(f Format) Option { return func(o *options) { o.format = f } }
+
+// AddSource makes the handler write the source of the record to the line.
+func AddSource(o *options) { o.addSource = true }
+
+// BatchSize sets the size of the lines in bytes, after which the batch
+// is pushed. Default is 1 MiB.
+func BatchSize(n int) Option { return func(o *options) { o.batchSize = n } }
+
+// BatchWait sets the maximum time the entry waits in the batch before it
+// is pushed. Default is 1 second, it is also used, if the time is not positive.
+func BatchWait(d time.Duration) Option { return func(o *options) { o.batchWait = d } }
+
+// MaxPending sets the maximum size of the lines in bytes, waiting to be
+// pushed, e.g. while the previous batch is retried during an outage.
+// Once it is exceeded, the oldest entries are dropped and the amount of
+// them is reported to OnError. Default is 16 MiB.
+func MaxPending(n int) Option { return func(o *options) { o.maxPending = n } }
+
+// MaxRetries sets the maximum amount of retries of the push, that failed
+// with the network error, 429 or 5xx status. Default is 5.
+func MaxRetries(n int) Option { return func(o *options) { o.maxRetries = n } }
+
+// Backoff sets the minimum and the maximum delay between the retries,
+// the delay doubles after each retry. The delay, requested by the server in
+// the Retry-After header, is used instead, if it doesn't exceed the maximum.
+// Default is 500ms and 30s.
+func Backoff(minDelay, maxDelay time.Duration) Option {
+	return func(o *options) { o.minBackoff, o.maxBackoff = minDelay, maxDelay }
+}
+
+// WithHTTPClient sets the HTTP client to push with. Default is the client
+// with 10 seconds timeout.
+func WithHTTPClient(c *http.Client) Option { return func(o *options) { o.client = c } }
+
+// Header adds the header to the push requests, e.g. "X-Scope-OrgID"
+// for multi-tenant Loki or "Authorization".
+func Header(key, value string) Option { return func(o *options) { o.header.Add(key, value) } }
+
+// OnError sets the function to report the errors of the background pushes.
+// By default, they are ignored.
+func OnError(fn func(error)) Option { return func(o *options) { o.onError = fn } }
+
+// Handler pushes records to Loki. Handlers, derived from it with WithAttrs
+// and WithGroup, share the same batch, so Flush and Close may be called
+// on any of them.
+type Handler struct {
+	c *client
+
+	enc     slog.Handler // encodes the lines to c.line
+	labels  map[string]string
+	grouped bool // whether there is an open group, so attributes can't be labels
+}
+
+// New returns a new Handler, that pushes records to the push API endpoint,
+// e.g. "http://localhost:3100/loki/api/v1/push".
+// Caller must call Close to push the last batch and stop the background
+// goroutine.
+func New(url string, opts ...Option) *Handler {
+	o := &options{
+		lvl:        slog.LevelInfo,
+		labelKeys:  []string{"service"},
+		batchSize:  1 << 20,
+		batchWait:  time.Second,
+		maxPending: 16 << 20,
+		maxRetries: 5,
+		minBackoff: 500 * time.Millisecond,
+		maxBackoff: 30 * time.Second,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		header:     http.Header{},
+		onError:    func(error) {},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.batchWait <= 0 {
+		o.batchWait = time.Second
+	}
+	static := make(map[string]string, len(o.staticLabels))
+	for k, v := range o.staticLabels {
+		static[promname.Sanitize(k)] = v
+	}
+	o.staticLabels = static
+
+	c := newClient(url, o)
+
+	hopts := &slog.HandlerOptions{
+		Level:     slog.LevelDebug - 1000, // level is checked by the Handler itself
+		AddSource: o.addSource,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+				return slog.Attr{} // time and level are sent separately
+			}
+			return a
+		},
+	}
+
+	var enc slog.Handler
+	switch o.format {
+	case FormatLogfmt:
+		enc = slog.NewTextHandler(&c.line, hopts)
+	default:
+		enc = slog.NewJSONHandler(&c.line, hopts)
+	}
+
+	return &Handler{c: c, enc: enc}
+}
+
+// Enabled reports whether the level is at or above the minimum one.
+func (h *Handler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.c.opts.lvl.Level()
+}
+
+// Handle adds the record to the batch.
+func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
+	labels := make(map[string]string, len(h.c.opts.staticLabels)+len(h.labels)+2)
+	maps.Copy(labels, h.c.opts.staticLabels)
+	maps.Copy(labels, h.labels)
+	labels["level"] = strings.ToLower(slogx.LevelName(rec.Level))
+
+	if !h.grouped {
+		rec = h.extractLabels(labels, rec)
+	}
+
+	line, err := h.c.encode(ctx, h.enc, rec)
+	if err != nil {
+		return err
+	}
+
+	return h.c.add(labels, rec.Time, line)
+}
+
+// WithAttrs returns a new Handler with the given attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	hh := *h
+
+	if !h.grouped {
+		var rest []slog.Attr
+		for _, a := range attrs {
+			if val, ok := h.labelValue(a); ok {
+				hh.labels = maps.Clone(hh.labels)
+				if hh.labels == nil {
+					hh.labels = map[string]string{}
+				}
+				hh.labels[promname.Sanitize(a.Key)] = val
+				continue
+			}
+			rest = append(rest, a)
+		}
+		attrs = rest
+	}
+
+	if len(attrs) > 0 {
+		hh.enc = h.enc.WithAttrs(attrs)
+	}
+
+	return &hh
+}
+
+// WithGroup returns a new Handler with the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	hh := *h
+	hh.enc = h.enc.WithGroup(name)
+	hh.grouped = true
+	return &hh
+}
+
+// Flush pushes the current batch and waits until it is pushed.
+func (h *Handler) Flush(ctx context.Context) error { return h.c.flush(ctx) }
+
+// Close stops the background pushes and pushes the last batch.
+// Records, passed to Handle after Close, are rejected with slogx.ErrClosed.
+func (h *Handler) Close(ctx context.Context) error { return h.c.close(ctx) }
+
+// extractLabels moves the label attributes of the record to the labels.
+func (h *Handler) extractLabels(labels map[string]string, rec slog.Record) slog.Record {
+	found := false
+	rec.Attrs(func(a slog.Attr) bool {
+		_, found = h.labelValue(a)
+		return !found
+	})
+	if !found {
+		return rec
+	}
+
+	nrec := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		if val, ok := h.labelValue(a); ok {
+			labels[promname.Sanitize(a.Key)] = val
+			return true
+		}
+		nrec.AddAttrs(a)
+		return true
+	})
+	return nrec
+}
+
+// labelValue returns the value of the label, if the attribute is a label one.
+func (h *Handler) labelValue(a slog.Attr) (string, bool) {
+	if !slices.Contains(h.c.opts.labelKeys, a.Key) {
+		return "", false
+	}
+
+	val := a.Value.Resolve()
+	if val.Kind() == slog.KindGroup {
+		return "", false
+	}
+
+	return val.String(), true
+}
@@ -0,0 +1,271 @@
+package loki
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+// lokiMock is a Loki push API, that records the requests and responds
+// with the given statuses, then with 204.
+type lokiMock struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	reqs     []pushRequest
+	headers  []http.Header
+	statuses []int
+}
+
+func newLokiMock(t *testing.T, statuses ...int) (*lokiMock, string) {
+	m := &lokiMock{t: t, statuses: statuses}
+	ts := httptest.NewServer(m)
+	t.Cleanup(ts.Close)
+	return m, ts.URL + "/loki/api/v1/push"
+}
+
+func (m *lokiMock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(m.t, "/loki/api/v1/push", r.URL.Path)
+	assert.Equal(m.t, "gzip", r.Header.Get("Content-Encoding"))
+	assert.Equal(m.t, "application/json", r.Header.Get("Content-Type"))
+
+	gz, err := gzip.NewReader(r.Body)
+	require.NoError(m.t, err)
+
+	var req pushRequest
+	require.NoError(m.t, json.NewDecoder(gz).Decode(&req))
+	sort.Slice(req.Streams, func(i, j int) bool { return streamKey(req.Streams[i].Stream) < streamKey(req.Streams[j].Stream) })
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reqs = append(m.reqs, req)
+	m.headers = append(m.headers, r.Header.Clone())
+
+	if len(m.statuses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	status := m.statuses[0]
+	m.statuses = m.statuses[1:]
+	if status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "1")
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+func (m *lokiMock) requests() []pushRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]pushRequest(nil), m.reqs...)
+}
+
+// lines returns the lines of the stream, without timestamps.
+func lines(s stream) []string {
+	res := make([]string, len(s.Values))
+	for i, v := range s.Values {
+		res[i] = v[1]
+	}
+	return res
+}
+
+func TestHandler_Streams(t *testing.T) {
+	m, url := newLokiMock(t)
+
+	h := New(url, BatchWait(time.Hour), StaticLabels(map[string]string{"env": "test"}),
+		LabelKeys("service", "user-id"), Header("X-Scope-OrgID", "tenant"))
+	defer h.Close(context.Background())
+
+	lg := slog.New(h).With("service", "api")
+	lg.Info("first", "user-id", 1, "k", "v")
+	lg.Info("second", "user-id", 1)
+	lg.Error("failed", slog.Group("user-id", slog.Int("not", 2)))
+	lg.WithGroup("g").Warn("grouped", "service", "not a label")
+	lg.Debug("disabled")
+
+	require.NoError(t, h.Flush(context.Background()))
+
+	reqs := m.requests()
+	require.Len(t, reqs, 1)
+	require.Len(t, reqs[0].Streams, 3)
+
+	assert.Equal(t, map[string]string{"env": "test", "level": "error", "service": "api"}, reqs[0].Streams[0].Stream)
+	assert.Equal(t, []string{`{"msg":"failed","user-id":{"not":2}}`}, lines(reqs[0].Streams[0]))
+
+	assert.Equal(t, map[string]string{"env": "test", "level": "info", "service": "api", "user_id": "1"}, reqs[0].Streams[1].Stream)
+	assert.Equal(t, []string{`{"msg":"first","k":"v"}`, `{"msg":"second"}`}, lines(reqs[0].Streams[1]))
+
+	assert.Equal(t, map[string]string{"env": "test", "level": "warn", "service": "api"}, reqs[0].Streams[2].Stream)
+	assert.Equal(t, []string{`{"msg":"grouped","g":{"service":"not a label"}}`}, lines(reqs[0].Streams[2]))
+
+	assert.Equal(t, "tenant", m.headers[0].Get("X-Scope-OrgID"))
+
+	// nothing to push
+	require.NoError(t, h.Flush(context.Background()))
+	assert.Len(t, m.requests(), 1)
+}
+
+func TestNew_Options(t *testing.T) {
+	m, url := newLokiMock(t)
+
+	h := New(url, BatchWait(0), StaticLabels(map[string]string{"deploy.env": "test"}))
+	defer h.Close(context.Background())
+	assert.Equal(t, time.Second, h.c.opts.batchWait)
+
+	slog.New(h).Info("message")
+	require.NoError(t, h.Flush(context.Background()))
+
+	require.Len(t, m.requests(), 1)
+	assert.Equal(t, map[string]string{"deploy_env": "test", "level": "info"}, m.requests()[0].Streams[0].Stream)
+}
+
+func TestHandler_Logfmt(t *testing.T) {
+	m, url := newLokiMock(t)
+
+	h := New(url, BatchWait(time.Hour), WithFormat(FormatLogfmt), WithLevel(slogx.LevelTrace))
+	defer h.Close(context.Background())
+
+	slogx.NewLogger(h).Trace("trace message", "k", "some value")
+	require.NoError(t, h.Flush(context.Background()))
+
+	reqs := m.requests()
+	require.Len(t, reqs, 1)
+	require.Len(t, reqs[0].Streams, 1)
+	assert.Equal(t, map[string]string{"level": "trace"}, reqs[0].Streams[0].Stream)
+	assert.Equal(t, []string{`msg="trace message" k="some value"`}, lines(reqs[0].Streams[0]))
+}
+
+func TestHandler_Batching(t *testing.T) {
+	t.Run("by size", func(t *testing.T) {
+		m, url := newLokiMock(t)
+		h := New(url, BatchWait(time.Hour), BatchSize(20))
+		defer h.Close(context.Background())
+
+		slog.New(h).Info("short")
+		time.Sleep(10 * time.Millisecond)
+		assert.Empty(t, m.requests())
+
+		slog.New(h).Info("long enough message")
+		require.Eventually(t, func() bool { return len(m.requests()) == 1 }, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []string{`{"msg":"short"}`, `{"msg":"long enough message"}`}, lines(m.requests()[0].Streams[0]))
+	})
+
+	t.Run("by time", func(t *testing.T) {
+		m, url := newLokiMock(t)
+		h := New(url, BatchWait(10*time.Millisecond))
+		defer h.Close(context.Background())
+
+		slog.New(h).Info("message")
+		require.Eventually(t, func() bool { return len(m.requests()) == 1 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("on close", func(t *testing.T) {
+		m, url := newLokiMock(t)
+		h := New(url, BatchWait(time.Hour))
+
+		slog.New(h).Info("message")
+		require.NoError(t, h.Close(context.Background()))
+		assert.Len(t, m.requests(), 1)
+
+		assert.ErrorIs(t, h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "closed", 0)), slogx.ErrClosed)
+		assert.NoError(t, h.Close(context.Background()))
+	})
+}
+
+func TestHandler_MaxPending(t *testing.T) {
+	m, url := newLokiMock(t)
+	var errs []error
+	h := New(url, BatchWait(time.Hour), MaxPending(50), OnError(func(err error) { errs = append(errs, err) }))
+	defer h.Close(context.Background())
+
+	lg := slog.New(h)
+	lg.Info("first")                                 // {"msg":"first"}, 15 bytes
+	lg.Info("second", slog.String("service", "api")) // another stream, 16 bytes
+	lg.Info("third")                                 // 15 bytes
+	lg.Info("fourth")                                // 16 bytes, drops "first"
+	require.NoError(t, h.Flush(context.Background()))
+
+	require.Len(t, m.requests(), 1)
+	var res []string
+	for _, s := range m.requests()[0].Streams {
+		res = append(res, lines(s)...)
+	}
+	assert.ElementsMatch(t, []string{`{"msg":"second"}`, `{"msg":"third"}`, `{"msg":"fourth"}`}, res)
+
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "1 entries dropped, pending entries exceeded 50 bytes")
+}
+
+func TestHandler_Retries(t *testing.T) {
+	t.Run("retried", func(t *testing.T) {
+		m, url := newLokiMock(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		h := New(url, BatchWait(time.Hour), Backoff(time.Millisecond, 10*time.Millisecond))
+		defer h.Close(context.Background())
+
+		slog.New(h).Info("message")
+		require.NoError(t, h.Flush(context.Background()))
+		assert.Len(t, m.requests(), 3)
+	})
+
+	t.Run("attempts exceeded", func(t *testing.T) {
+		m, url := newLokiMock(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusInternalServerError)
+		h := New(url, BatchWait(time.Hour), MaxRetries(2), Backoff(time.Millisecond, time.Millisecond))
+		defer h.Close(context.Background())
+
+		slog.New(h).Info("message")
+		assert.ErrorContains(t, h.Flush(context.Background()), "push batch, 3 attempts failed: unexpected status 500")
+		assert.Len(t, m.requests(), 3)
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		m, url := newLokiMock(t, http.StatusBadRequest)
+		h := New(url, BatchWait(time.Hour), Backoff(time.Millisecond, time.Millisecond))
+		defer h.Close(context.Background())
+
+		slog.New(h).Info("message")
+		assert.ErrorContains(t, h.Flush(context.Background()), "push batch: unexpected status 400: Bad Request")
+		assert.Len(t, m.requests(), 1)
+	})
+
+	t.Run("background errors", func(t *testing.T) {
+		_, url := newLokiMock(t, http.StatusBadRequest)
+		errs := make(chan error, 1)
+		h := New(url, BatchWait(10*time.Millisecond), OnError(func(err error) { errs <- err }))
+		defer h.Close(context.Background())
+
+		slog.New(h).Info("message")
+		select {
+		case err := <-errs:
+			assert.ErrorContains(t, err, "unexpected status 400")
+		case <-time.After(time.Second):
+			t.Fatal("error is not reported")
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		_, url := newLokiMock(t, http.StatusServiceUnavailable)
+		h := New(url, BatchWait(time.Hour), Backoff(time.Hour, time.Hour))
+		defer h.Close(context.Background())
+
+		slog.New(h).Info("message")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, h.Flush(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,52 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+)
+
+// When returns a Middleware that applies mw only to the records,
+// matching the predicate, the rest of them are passed to the next
+// handler as is.
+func When(p Predicate, mw Middleware) Middleware {
+	return Either(p, mw, nil)
+}
+
+// Unless returns a Middleware that applies mw only to the records,
+// that don't match the predicate.
+func Unless(p Predicate, mw Middleware) Middleware {
+	return Either(p, nil, mw)
+}
+
+// Either returns a Middleware that applies a to the records, matching
+// the predicate, and b to the rest of them. Nil middleware passes
+// records to the next handler as is.
+func Either(p Predicate, a, b Middleware) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		matched, rest := next, next
+		if a != nil {
+			matched = a(next)
+		}
+		if b != nil {
+			rest = b(next)
+		}
+
+		return func(ctx context.Context, rec slog.Record) error {
+			if p(ctx, rec) {
+				return matched(ctx, rec)
+			}
+			return rest(ctx, rec)
+		}
+	}
+}
+
+// Compose bundles the middlewares into one, which applies them
+// in the same order as Chain does: the first one is the outermost.
+func Compose(mws ...Middleware) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
@@ -0,0 +1,77 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addAttr(key, value string) Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error {
+			rec.AddAttrs(slog.String(key, value))
+			return next(ctx, rec)
+		}
+	}
+}
+
+func handleAttrs(t *testing.T, mw Middleware, attrs ...slog.Attr) map[string]string {
+	t.Helper()
+
+	res := map[string]string{}
+	h := mw(func(_ context.Context, rec slog.Record) error {
+		rec.Attrs(func(attr slog.Attr) bool {
+			res[attr.Key] += attr.Value.String()
+			return true
+		})
+		return nil
+	})
+
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "test", 0)
+	rec.AddAttrs(attrs...)
+	require.NoError(t, h(context.Background(), rec))
+	return res
+}
+
+func TestWhen(t *testing.T) {
+	mw := When(HasAttr("audit"), addAttr("x", "1"))
+	assert.Equal(t, map[string]string{"audit": "true", "x": "1"}, handleAttrs(t, mw, slog.Bool("audit", true)))
+	assert.Equal(t, map[string]string{"a": "b"}, handleAttrs(t, mw, slog.String("a", "b")))
+}
+
+func TestUnless(t *testing.T) {
+	mw := Unless(HasAttr("audit"), addAttr("x", "1"))
+	assert.Equal(t, map[string]string{"audit": "true"}, handleAttrs(t, mw, slog.Bool("audit", true)))
+	assert.Equal(t, map[string]string{"a": "b", "x": "1"}, handleAttrs(t, mw, slog.String("a", "b")))
+}
+
+func TestEither(t *testing.T) {
+	mw := Either(HasAttr("audit"), addAttr("x", "a"), addAttr("x", "b"))
+	assert.Equal(t, map[string]string{"audit": "true", "x": "a"}, handleAttrs(t, mw, slog.Bool("audit", true)))
+	assert.Equal(t, map[string]string{"a": "b", "x": "b"}, handleAttrs(t, mw, slog.String("a", "b")))
+}
+
+func TestCompose(t *testing.T) {
+	mw := Compose(addAttr("x", "1"), addAttr("x", "2"), addAttr("x", "3"))
+	assert.Equal(t, map[string]string{"x": "123"}, handleAttrs(t, mw))
+
+	assert.Equal(t, map[string]string{"a": "b"}, handleAttrs(t, Compose(), slog.String("a", "b")))
+
+	// composes with the rest of the chain in the same order
+	var order []string
+	rec := func(name string) Middleware {
+		return func(next HandleFunc) HandleFunc {
+			return func(ctx context.Context, r slog.Record) error {
+				order = append(order, name)
+				return next(ctx, r)
+			}
+		}
+	}
+	lg := slog.New(NewChain(nopHandlerFunc(), rec("1"), Compose(rec("2"), rec("3")), rec("4")))
+	lg.Info("test")
+	assert.Equal(t, []string{"1", "2", "3", "4"}, order)
+}
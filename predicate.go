@@ -0,0 +1,193 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Predicate reports whether the record matches some condition.
+type Predicate func(context.Context, slog.Record) bool
+
+// And returns a Predicate that matches if all the given predicates match.
+func And(ps ...Predicate) Predicate {
+	return func(ctx context.Context, rec slog.Record) bool {
+		for _, p := range ps {
+			if !p(ctx, rec) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a Predicate that matches if any of the given predicates matches.
+func Or(ps ...Predicate) Predicate {
+	return func(ctx context.Context, rec slog.Record) bool {
+		for _, p := range ps {
+			if p(ctx, rec) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a Predicate that matches if the given one doesn't.
+func Not(p Predicate) Predicate {
+	return func(ctx context.Context, rec slog.Record) bool { return !p(ctx, rec) }
+}
+
+// LevelAtLeast returns a Predicate that matches records with the level
+// not less than lvl.
+func LevelAtLeast(lvl slog.Leveler) Predicate {
+	return func(_ context.Context, rec slog.Record) bool { return rec.Level >= lvl.Level() }
+}
+
+// MessageMatches returns a Predicate that matches records with the message
+// matching the regular expression.
+func MessageMatches(re *regexp.Regexp) Predicate {
+	return func(_ context.Context, rec slog.Record) bool { return re.MatchString(rec.Message) }
+}
+
+// HasAttr returns a Predicate that matches records with the attribute at the
+// given path, where the path is the dot-separated list of groups and the key
+// of the attribute, e.g. "request.method".
+func HasAttr(path string) Predicate {
+	keys := strings.Split(path, ".")
+	return func(_ context.Context, rec slog.Record) bool {
+		_, ok := findAttr(rec, keys)
+		return ok
+	}
+}
+
+// AttrEquals returns a Predicate that matches records with the attribute at the
+// given path (see HasAttr) equal to v.
+func AttrEquals(path string, v any) Predicate {
+	keys := strings.Split(path, ".")
+	want := slog.AnyValue(v)
+	return func(_ context.Context, rec slog.Record) bool {
+		attr, ok := findAttr(rec, keys)
+		return ok && valuesEqual(attr.Value, want)
+	}
+}
+
+// ContextValue returns a Predicate that matches records, logged with
+// the context, that contains the given value under the given key.
+func ContextValue(key, v any) Predicate {
+	return func(ctx context.Context, _ slog.Record) bool {
+		return reflect.DeepEqual(ctx.Value(key), v)
+	}
+}
+
+// SourceFile returns a Predicate that matches records, logged from the files
+// with the path matching the regular expression.
+func SourceFile(re *regexp.Regexp) Predicate {
+	return func(_ context.Context, rec slog.Record) bool {
+		fr, ok := sourceFrame(rec)
+		return ok && re.MatchString(fr.File)
+	}
+}
+
+// SourcePackage returns a Predicate that matches records, logged from any of
+// the packages with the given import paths. The path, that ends with "/...",
+// matches the package and all of its subpackages, e.g. "github.com/org/app/...".
+func SourcePackage(pkgs ...string) Predicate {
+	return func(_ context.Context, rec slog.Record) bool {
+		fr, ok := sourceFrame(rec)
+		if !ok {
+			return false
+		}
+
+		pkg := funcPackage(fr.Function)
+		for _, p := range pkgs {
+			if prefix, found := strings.CutSuffix(p, "/..."); found {
+				if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+					return true
+				}
+				continue
+			}
+			if pkg == p {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// sourceFrame returns the frame of the record's call site, if it is known.
+func sourceFrame(rec slog.Record) (runtime.Frame, bool) {
+	if rec.PC == 0 {
+		return runtime.Frame{}, false
+	}
+	fr, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+	return fr, fr.Function != ""
+}
+
+// funcPackage returns the import path of the package from the
+// fully qualified function name, e.g. "github.com/org/app/pkg.(*T).Method",
+// dots in the last element of the path are escaped by the linker as "%2e".
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	if dot := strings.IndexByte(fn[slash+1:], '.'); dot >= 0 {
+		fn = fn[:slash+1+dot]
+	}
+	return strings.ReplaceAll(fn, "%2e", ".")
+}
+
+// findAttr looks for the attribute at the path of keys in the record,
+// the attributes of groups with empty keys are treated as the attributes
+// of their parents.
+func findAttr(rec slog.Record, keys []string) (res slog.Attr, found bool) {
+	rec.Attrs(func(attr slog.Attr) bool {
+		res, found = findInAttr(attr, keys)
+		return !found
+	})
+	return res, found
+}
+
+func findInAttr(attr slog.Attr, keys []string) (slog.Attr, bool) {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Key == "" && attr.Value.Kind() == slog.KindGroup {
+		return findInGroup(attr.Value.Group(), keys)
+	}
+
+	if attr.Key != keys[0] {
+		return slog.Attr{}, false
+	}
+
+	if len(keys) == 1 {
+		return attr, true
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		return slog.Attr{}, false
+	}
+
+	return findInGroup(attr.Value.Group(), keys[1:])
+}
+
+func findInGroup(attrs []slog.Attr, keys []string) (slog.Attr, bool) {
+	for _, a := range attrs {
+		if res, ok := findInAttr(a, keys); ok {
+			return res, true
+		}
+	}
+	return slog.Attr{}, false
+}
+
+// valuesEqual compares the values without panicking on the
+// values of uncomparable types.
+func valuesEqual(a, b slog.Value) bool {
+	if a.Kind() != b.Kind() {
+		return false
+	}
+	if a.Kind() == slog.KindAny {
+		return reflect.DeepEqual(a.Any(), b.Any())
+	}
+	return a.Equal(b)
+}
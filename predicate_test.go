@@ -0,0 +1,100 @@
+package slogx
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type predicateCtxKey struct{}
+
+func TestPredicates(t *testing.T) {
+	rec := slog.NewRecord(time.Now(), slog.LevelWarn, "payment failed", 0)
+	rec.AddAttrs(
+		slog.Bool("audit", true),
+		slog.Group("request", slog.String("method", "POST"), slog.Group("user", slog.Int("id", 42))),
+		slog.Group("", slog.String("inline", "v")),
+		slog.Any("tags", []string{"a", "b"}),
+	)
+	ctx := context.WithValue(context.Background(), predicateCtxKey{}, "tenant")
+
+	tests := []struct {
+		name string
+		p    Predicate
+		want bool
+	}{
+		{name: "level", p: LevelAtLeast(slog.LevelWarn), want: true},
+		{name: "level above", p: LevelAtLeast(slog.LevelError), want: false},
+		{name: "message", p: MessageMatches(regexp.MustCompile("^payment")), want: true},
+		{name: "message mismatch", p: MessageMatches(regexp.MustCompile("^user")), want: false},
+		{name: "has attr", p: HasAttr("audit"), want: true},
+		{name: "has nested attr", p: HasAttr("request.user.id"), want: true},
+		{name: "has group", p: HasAttr("request.user"), want: true},
+		{name: "has inline attr", p: HasAttr("inline"), want: true},
+		{name: "no attr", p: HasAttr("request.path"), want: false},
+		{name: "path through non-group", p: HasAttr("audit.x"), want: false},
+		{name: "attr equals", p: AttrEquals("audit", true), want: true},
+		{name: "nested attr equals", p: AttrEquals("request.user.id", 42), want: true},
+		{name: "attr not equals", p: AttrEquals("request.method", "GET"), want: false},
+		{name: "attr of another kind", p: AttrEquals("audit", "true"), want: false},
+		{name: "uncomparable attr", p: AttrEquals("tags", []string{"a", "b"}), want: true},
+		{name: "context value", p: ContextValue(predicateCtxKey{}, "tenant"), want: true},
+		{name: "context value mismatch", p: ContextValue(predicateCtxKey{}, "other"), want: false},
+		{name: "and", p: And(HasAttr("audit"), LevelAtLeast(slog.LevelWarn)), want: true},
+		{name: "and mismatch", p: And(HasAttr("audit"), HasAttr("nope")), want: false},
+		{name: "or", p: Or(HasAttr("nope"), HasAttr("audit")), want: true},
+		{name: "or mismatch", p: Or(HasAttr("nope"), HasAttr("nope2")), want: false},
+		{name: "not", p: Not(HasAttr("nope")), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.p(ctx, rec))
+		})
+	}
+}
+
+func TestSourcePredicates(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(1, pcs[:])
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "test", pcs[0])
+
+	tests := []struct {
+		name string
+		p    Predicate
+		want bool
+	}{
+		{name: "file", p: SourceFile(regexp.MustCompile(`predicate_test\.go$`)), want: true},
+		{name: "file mismatch", p: SourceFile(regexp.MustCompile(`router\.go$`)), want: false},
+		{name: "package", p: SourcePackage("github.com/cappuccinotm/slogx"), want: true},
+		{name: "any of packages", p: SourcePackage("github.com/cappuccinotm/slogx/slogm", "github.com/cappuccinotm/slogx"), want: true},
+		{name: "subpackages", p: SourcePackage("github.com/cappuccinotm/..."), want: true},
+		{name: "package itself", p: SourcePackage("github.com/cappuccinotm/slogx/..."), want: true},
+		{name: "package mismatch", p: SourcePackage("github.com/cappuccinotm/slogx/slogm"), want: false},
+		{name: "prefix is not a parent", p: SourcePackage("github.com/cappuccinotm/slog/..."), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.p(context.Background(), rec))
+		})
+	}
+
+	t.Run("unknown source", func(t *testing.T) {
+		rec := slog.NewRecord(time.Now(), slog.LevelInfo, "test", 0)
+		assert.False(t, SourceFile(regexp.MustCompile(`.*`))(context.Background(), rec))
+		assert.False(t, SourcePackage("github.com/cappuccinotm/...")(context.Background(), rec))
+	})
+}
+
+func TestFuncPackage(t *testing.T) {
+	assert.Equal(t, "github.com/org/app/pkg", funcPackage("github.com/org/app/pkg.(*T).Method"))
+	assert.Equal(t, "github.com/org/app/pkg", funcPackage("github.com/org/app/pkg.Func.func1"))
+	assert.Equal(t, "main", funcPackage("main.main"))
+	assert.Equal(t, "gopkg.in/yaml.v3", funcPackage("gopkg.in/yaml%2ev3.Unmarshal"))
+}
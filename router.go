@@ -0,0 +1,193 @@
+package slogx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+)
+
+// Rule is a route of the Router.
+type Rule struct {
+	Match   Predicate
+	Handler slog.Handler
+}
+
+type routerOptions struct {
+	all bool
+}
+
+// RouterOption is a functional option for NewRouter.
+type RouterOption func(*Router)
+
+// Route adds a rule to the Router, that dispatches the records,
+// matching the predicate, to the handler.
+// Rules are evaluated in the order they were added.
+func Route(match Predicate, h slog.Handler) RouterOption {
+	return func(r *Router) { r.rules = append(r.rules, Rule{Match: match, Handler: h}) }
+}
+
+// RouteFallback sets the handler for the records, that didn't match any rule.
+// By default, such records are dropped.
+func RouteFallback(h slog.Handler) RouterOption {
+	return func(r *Router) { r.fallback = h }
+}
+
+// DispatchAll configures the Router to dispatch the record to all the matched
+// rules, instead of the first one.
+func DispatchAll(r *Router) { r.opts.all = true }
+
+// Router is a handler that dispatches records to the handlers by the rules.
+// By default, the record is dispatched to the first matched rule, regardless
+// of whether its handler is enabled for the record's level.
+//
+// Rules are evaluated against the record's attributes together with the ones
+// of the logger, passed with WithAttrs, e.g. the records of
+// logger.With("audit", true) match AttrEquals("audit", true).
+// The record's attributes are matched by their keys within the groups
+// of the logger, while the logger's ones are matched by their full path,
+// e.g. "request.method" for logger.WithGroup("request").With("method", "GET").
+type Router struct {
+	opts     routerOptions
+	rules    []Rule
+	fallback slog.Handler
+
+	attrs  []slog.Attr // attributes of the logger, nested into their groups
+	groups []string    // groups of the logger
+}
+
+// NewRouter makes a new Router.
+func NewRouter(opts ...RouterOption) *Router {
+	r := &Router{}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Enabled returns true if any of the handlers is enabled for the level.
+func (r *Router) Enabled(ctx context.Context, lvl slog.Level) bool {
+	for _, rule := range r.rules {
+		if rule.Handler.Enabled(ctx, lvl) {
+			return true
+		}
+	}
+	return r.fallback != nil && r.fallback.Enabled(ctx, lvl)
+}
+
+// Handle dispatches the record to the matched rules, or to the fallback handler.
+func (r *Router) Handle(ctx context.Context, rec slog.Record) error {
+	matched, err := r.dispatch(ctx, rec)
+	if matched || r.fallback == nil || !r.fallback.Enabled(ctx, rec.Level) {
+		return err
+	}
+	return r.fallback.Handle(ctx, rec)
+}
+
+// WithAttrs returns a new Router with the given attributes applied to all the handlers.
+func (r *Router) WithAttrs(attrs []slog.Attr) slog.Handler { return r.withAttrs(attrs) }
+
+// WithGroup returns a new Router with the given group applied to all the handlers.
+func (r *Router) WithGroup(name string) slog.Handler { return r.withGroup(name) }
+
+// Interceptor returns the Router as the Interceptor to use in a Chain:
+// the records, matched by the rules, are dispatched to the rules' handlers,
+// while the rest of them are passed down the chain. Fallback is not used.
+func (r *Router) Interceptor() Interceptor { return routerInterceptor{r: r} }
+
+// dispatch passes the record to the matched rules and
+// reports whether there was any match.
+func (r *Router) dispatch(ctx context.Context, rec slog.Record) (matched bool, err error) {
+	m := r.matchRecord(rec)
+
+	var errs []error
+	for _, rule := range r.rules {
+		if !rule.Match(ctx, m) {
+			continue
+		}
+
+		matched = true
+		if rule.Handler.Enabled(ctx, rec.Level) {
+			if err := rule.Handler.Handle(ctx, rec.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if !r.opts.all {
+			break
+		}
+	}
+	return matched, errors.Join(errs...)
+}
+
+// matchRecord returns the record to evaluate the rules against, with
+// the attributes of the logger in addition to the record's ones.
+func (r *Router) matchRecord(rec slog.Record) slog.Record {
+	if len(r.attrs) == 0 {
+		return rec
+	}
+
+	m := slog.NewRecord(rec.Time, rec.Level, rec.Message, rec.PC)
+	m.AddAttrs(Attrs(rec)...)
+	m.AddAttrs(r.attrs...)
+	return m
+}
+
+func (r *Router) withAttrs(attrs []slog.Attr) *Router {
+	rr := r.derive(func(h slog.Handler) slog.Handler { return h.WithAttrs(attrs) })
+	rr.attrs = append(slices.Clip(r.attrs), nestAttrs(r.groups, attrs)...)
+	return rr
+}
+
+func (r *Router) withGroup(name string) *Router {
+	rr := r.derive(func(h slog.Handler) slog.Handler { return h.WithGroup(name) })
+	if name != "" {
+		rr.groups = append(slices.Clip(r.groups), name)
+	}
+	return rr
+}
+
+func (r *Router) derive(fn func(slog.Handler) slog.Handler) *Router {
+	rr := &Router{opts: r.opts, rules: make([]Rule, len(r.rules)), attrs: r.attrs, groups: r.groups}
+	for i, rule := range r.rules {
+		rr.rules[i] = Rule{Match: rule.Match, Handler: fn(rule.Handler)}
+	}
+	if r.fallback != nil {
+		rr.fallback = fn(r.fallback)
+	}
+	return rr
+}
+
+type routerInterceptor struct{ r *Router }
+
+// Wrap dispatches the matched records to the rules and passes the rest down the chain.
+func (ri routerInterceptor) Wrap(next HandleFunc) HandleFunc {
+	return func(ctx context.Context, rec slog.Record) error {
+		matched, err := ri.r.dispatch(ctx, rec)
+		if matched {
+			return err
+		}
+		return next(ctx, rec)
+	}
+}
+
+// WithAttrs applies the attributes to the rules' handlers and passes them down the chain.
+func (ri routerInterceptor) WithAttrs(attrs []slog.Attr) (Interceptor, []slog.Attr) {
+	return routerInterceptor{r: ri.r.withAttrs(attrs)}, attrs
+}
+
+// WithGroup applies the group to the rules' handlers and passes it down the chain.
+func (ri routerInterceptor) WithGroup(name string) (Interceptor, string) {
+	return routerInterceptor{r: ri.r.withGroup(name)}, name
+}
+
+// nestAttrs nests the attributes into the groups.
+func nestAttrs(groups []string, attrs []slog.Attr) []slog.Attr {
+	if len(attrs) == 0 {
+		return nil
+	}
+	for i := len(groups) - 1; i >= 0; i-- {
+		attrs = []slog.Attr{{Key: groups[i], Value: slog.GroupValue(attrs...)}}
+	}
+	return attrs
+}
@@ -0,0 +1,186 @@
+package slogx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx/slogt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Handle(t *testing.T) {
+	t.Run("first match", func(t *testing.T) {
+		audit, access, def := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+		r := NewRouter(
+			Route(AttrEquals("audit", true), slog.NewJSONHandler(audit, nil)),
+			Route(HasAttr("request.method"), slog.NewJSONHandler(access, nil)),
+			RouteFallback(slog.NewJSONHandler(def, nil)),
+		)
+
+		lg := slog.New(r)
+		lg.Info("user deleted", slog.Bool("audit", true), slog.Group("request", slog.String("method", "DELETE")))
+		lg.Info("http server request", slog.Group("request", slog.String("method", "GET")))
+		lg.Info("regular message")
+
+		assert.Contains(t, audit.String(), "user deleted")
+		assert.NotContains(t, audit.String(), "http server request")
+		assert.NotContains(t, access.String(), "user deleted")
+		assert.Contains(t, access.String(), "http server request")
+		assert.Contains(t, def.String(), "regular message")
+		assert.NotContains(t, def.String(), "user deleted")
+		assert.NotContains(t, def.String(), "http server request")
+	})
+
+	t.Run("all matches", func(t *testing.T) {
+		errs, msgs, def := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+		r := NewRouter(
+			Route(LevelAtLeast(slog.LevelError), slog.NewJSONHandler(errs, nil)),
+			Route(MessageMatches(regexp.MustCompile("^payment")), slog.NewJSONHandler(msgs, nil)),
+			RouteFallback(slog.NewJSONHandler(def, nil)),
+			DispatchAll,
+		)
+
+		lg := slog.New(r)
+		lg.Error("payment failed")
+		lg.Info("payment succeeded")
+
+		assert.Contains(t, errs.String(), "payment failed")
+		assert.NotContains(t, errs.String(), "payment succeeded")
+		assert.Contains(t, msgs.String(), "payment failed")
+		assert.Contains(t, msgs.String(), "payment succeeded")
+		assert.Empty(t, def.String())
+	})
+
+	t.Run("no match without fallback", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		r := NewRouter(Route(HasAttr("audit"), slog.NewJSONHandler(buf, nil)))
+		require.NoError(t, r.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "test", 0)))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("matched but disabled", func(t *testing.T) {
+		buf, def := &bytes.Buffer{}, &bytes.Buffer{}
+		r := NewRouter(
+			Route(HasAttr("audit"), slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelWarn})),
+			RouteFallback(slog.NewJSONHandler(def, nil)),
+		)
+		slog.New(r).Info("test", slog.Bool("audit", true))
+		assert.Empty(t, buf.String())
+		assert.Empty(t, def.String())
+	})
+
+	t.Run("errors are joined", func(t *testing.T) {
+		err1, err2 := errors.New("err1"), errors.New("err2")
+		r := NewRouter(
+			Route(HasAttr("a"), slogt.HandlerFunc(func(context.Context, slog.Record) error { return err1 })),
+			Route(HasAttr("a"), slogt.HandlerFunc(func(context.Context, slog.Record) error { return err2 })),
+			DispatchAll,
+		)
+
+		rec := slog.NewRecord(time.Now(), slog.LevelInfo, "test", 0)
+		rec.AddAttrs(slog.String("a", "1"))
+		err := r.Handle(context.Background(), rec)
+		assert.ErrorIs(t, err, err1)
+		assert.ErrorIs(t, err, err2)
+	})
+}
+
+func TestRouter_Enabled(t *testing.T) {
+	r := NewRouter(
+		Route(HasAttr("a"), slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn})),
+	)
+	assert.False(t, r.Enabled(context.Background(), slog.LevelInfo))
+	assert.True(t, r.Enabled(context.Background(), slog.LevelWarn))
+
+	r = NewRouter(
+		Route(HasAttr("a"), slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelWarn})),
+		RouteFallback(slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	)
+	assert.True(t, r.Enabled(context.Background(), slog.LevelDebug))
+}
+
+func TestRouter_WithAttrs(t *testing.T) {
+	buf, def := &bytes.Buffer{}, &bytes.Buffer{}
+	r := NewRouter(
+		Route(HasAttr("audit"), slog.NewJSONHandler(buf, nil)),
+		RouteFallback(slog.NewJSONHandler(def, nil)),
+	)
+
+	lg := slog.New(r).With(slog.String("service", "billing")).WithGroup("g")
+	lg.Info("audit", slog.Bool("audit", true))
+	lg.Info("regular")
+
+	var entry map[string]any
+	require.NoError(t, json.NewDecoder(buf).Decode(&entry))
+	assert.Equal(t, "billing", entry["service"])
+	assert.Equal(t, map[string]any{"audit": true}, entry["g"])
+
+	entry = nil
+	require.NoError(t, json.NewDecoder(def).Decode(&entry))
+	assert.Equal(t, "billing", entry["service"])
+	assert.Equal(t, "regular", entry["msg"])
+}
+
+func TestRouter_Interceptor(t *testing.T) {
+	audit, def := &bytes.Buffer{}, &bytes.Buffer{}
+	r := NewRouter(
+		Route(AttrEquals("audit", true), slog.NewJSONHandler(audit, nil)),
+		RouteFallback(slog.NewJSONHandler(&bytes.Buffer{}, nil)), // must be ignored
+	)
+
+	lg := slog.New(NewChain(slog.NewJSONHandler(def, nil)).Use(r.Interceptor())).
+		With(slog.String("service", "billing"))
+	lg.Info("audit message", slog.Bool("audit", true))
+	lg.Info("regular message", slog.Bool("audit", false))
+
+	var entry map[string]any
+	require.NoError(t, json.NewDecoder(audit).Decode(&entry))
+	assert.Equal(t, "audit message", entry["msg"])
+	assert.Equal(t, "billing", entry["service"])
+
+	entry = nil
+	require.NoError(t, json.NewDecoder(def).Decode(&entry))
+	assert.Equal(t, "regular message", entry["msg"])
+	assert.Equal(t, "billing", entry["service"])
+	assert.False(t, json.NewDecoder(def).More())
+}
+
+func TestRouter_MatchLoggerAttrs(t *testing.T) {
+	audit, access, def := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	r := NewRouter(
+		Route(AttrEquals("audit", true), slog.NewJSONHandler(audit, nil)),
+		Route(AttrEquals("request.method", "GET"), slog.NewJSONHandler(access, nil)),
+		RouteFallback(slog.NewJSONHandler(def, nil)),
+	)
+
+	lg := slog.New(r)
+	lg.With("audit", true).Info("user deleted")
+	lg.WithGroup("request").With("method", "GET").Info("http server request")
+	lg.With("audit", false).Info("regular message")
+
+	assert.Contains(t, audit.String(), "user deleted")
+	assert.Contains(t, access.String(), "http server request")
+	assert.Contains(t, def.String(), "regular message")
+	assert.NotContains(t, def.String(), "user deleted")
+	assert.NotContains(t, def.String(), "http server request")
+
+	t.Run("interceptor", func(t *testing.T) {
+		audit, def := &bytes.Buffer{}, &bytes.Buffer{}
+		r := NewRouter(Route(AttrEquals("audit", true), slog.NewJSONHandler(audit, nil)))
+
+		lg := slog.New(NewChain(slog.NewJSONHandler(def, nil)).Use(r.Interceptor()))
+		lg.With("audit", true).Info("audit message")
+		lg.Info("regular message")
+
+		assert.Contains(t, audit.String(), "audit message")
+		assert.NotContains(t, def.String(), "audit message")
+		assert.Contains(t, def.String(), "regular message")
+	})
+}
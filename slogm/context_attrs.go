@@ -0,0 +1,19 @@
+package slogm
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// ContextAttrs returns a middleware that adds the attributes, stored in the
+// context with slogx.ContextWithAttrs and slogx.ContextField, to the record.
+func ContextAttrs() slogx.Middleware {
+	return func(next slogx.HandleFunc) slogx.HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error {
+			rec.AddAttrs(slogx.AttrsFromContext(ctx)...)
+			return next(ctx, rec)
+		}
+	}
+}
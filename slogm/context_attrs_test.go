@@ -0,0 +1,43 @@
+package slogm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextAttrs(t *testing.T) {
+	tenantID := slogx.NewContextField[string]("tenant_id")
+	jobID := slogx.NewContextField[int]("job_id")
+
+	buf := &bytes.Buffer{}
+	lg := slog.New(slogx.NewChain(slog.NewJSONHandler(buf, nil), ContextAttrs()))
+
+	ctx := tenantID.With(context.Background(), "tenant")
+	ctx = slogx.ContextWithAttrs(ctx, slog.Group("user", slog.String("id", "1")))
+	ctx = jobID.With(ctx, 42)
+	ctx = slogx.ContextWithAttrs(ctx, slog.Group("user", slog.String("role", "admin")))
+	lg.InfoContext(ctx, "test")
+
+	t.Log(buf.String())
+
+	var entry struct {
+		TenantID string `json:"tenant_id"`
+		JobID    int    `json:"job_id"`
+		User     struct {
+			ID   string `json:"id"`
+			Role string `json:"role"`
+		} `json:"user"`
+	}
+	require.NoError(t, json.NewDecoder(buf).Decode(&entry))
+	assert.Equal(t, "tenant", entry.TenantID)
+	assert.Equal(t, 42, entry.JobID)
+	assert.Equal(t, "1", entry.User.ID)
+	assert.Equal(t, "admin", entry.User.Role)
+}
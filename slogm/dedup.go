@@ -0,0 +1,332 @@
+package slogm
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/fnv"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// Keys of the attributes, added to the follow-up record by Deduplicator.
+const (
+	RepeatCountKey = "repeat_count"
+	FirstSeenKey   = "first_seen"
+	LastSeenKey    = "last_seen"
+)
+
+type dedupOptions struct {
+	keys       map[string]struct{}
+	onError    func(error)
+	maxEntries int
+}
+
+// DedupOption is a functional option for Dedup.
+type DedupOption func(*dedupOptions)
+
+// DedupKeys sets the attributes to fingerprint records by, in addition to
+// their level and message. Attributes inside groups are referred by the
+// dot-separated path, e.g. "request.method". By default, all attributes are used.
+func DedupKeys(keys ...string) DedupOption {
+	return func(o *dedupOptions) {
+		o.keys = make(map[string]struct{}, len(keys))
+		for _, k := range keys {
+			o.keys[k] = struct{}{}
+		}
+	}
+}
+
+// DedupMaxEntries sets the maximum amount of fingerprints, tracked at once.
+// Once it is reached, the oldest window is closed before its time, i.e.
+// its follow-up record is passed right away. Default is 10000.
+func DedupMaxEntries(n int) DedupOption { return func(o *dedupOptions) { o.maxEntries = n } }
+
+// DedupOnError sets the function to call with errors returned by the chain
+// on passing the follow-up records. By default, errors are ignored.
+func DedupOnError(fn func(error)) DedupOption { return func(o *dedupOptions) { o.onError = fn } }
+
+// Deduplicator is a middleware that collapses repeated records.
+// Only the first of the records with the same fingerprint is passed within the
+// window, which starts with it, and once the window is closed, if there were
+// any repeats, a copy of the first record is passed down the chain with the
+// amount of repeats, time of the first and the last occurrences under the
+// RepeatCountKey, FirstSeenKey and LastSeenKey attributes.
+//
+// Records are deduplicated per composition of the chain, i.e. records of the
+// loggers with different attributes and groups are never collapsed together,
+// and the follow-up record is passed through the same chain the first one was.
+//
+// It is a slogx.Interceptor, so it can be used in chains either as
+// slogx.NewChain(h).Use(dedup) or as slogx.NewChain(h, dedup.Wrap), and it must
+// be closed with Close to flush the pending follow-up records. In the former
+// case it sees the attributes and groups of the loggers, so the records of
+// the loggers, derived with the same ones, are collapsed together, in the
+// latter one each derived logger deduplicates its own records.
+// A Deduplicator must not be used in more than one chain.
+type Deduplicator struct {
+	window time.Duration
+	opts   dedupOptions
+	state  *dedupState
+
+	groups []string       // groups of the logger, the record's attributes are in
+	scope  uint64         // hash of the logger's attributes and groups
+	wraps  *atomic.Uint64 // amount of chains, built with this scope
+}
+
+type dedupState struct {
+	mu      sync.Mutex
+	closed  bool
+	entries map[uint64]*dedupEntry
+	order   *list.List // of *dedupEntry, oldest first
+
+	compositions atomic.Uint64 // sequence of the compositions without a known scope
+}
+
+type dedupEntry struct {
+	fp    uint64
+	el    *list.Element
+	ctx   context.Context
+	next  slogx.HandleFunc
+	rec   slog.Record
+	count int
+	first time.Time
+	last  time.Time
+	timer *time.Timer
+}
+
+// Dedup makes a new Deduplicator with the given window.
+func Dedup(window time.Duration, opts ...DedupOption) *Deduplicator {
+	o := dedupOptions{onError: func(error) {}, maxEntries: 10000}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Deduplicator{
+		window: window,
+		opts:   o,
+		state:  &dedupState{entries: map[uint64]*dedupEntry{}, order: list.New()},
+		wraps:  &atomic.Uint64{},
+	}
+}
+
+// Wrap is a slogx.Middleware that deduplicates records.
+func (d *Deduplicator) Wrap(next slogx.HandleFunc) slogx.HandleFunc {
+	composition := d.scope
+	if d.wraps.Add(1) > 1 {
+		// the chain is derived without telling the attributes, e.g. when
+		// it is used as dedup.Wrap, so its records must be kept apart
+		composition = hashUint64(d.scope, d.state.compositions.Add(1))
+	}
+
+	return func(ctx context.Context, rec slog.Record) error {
+		t := rec.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+
+		fp := d.fingerprint(composition, rec)
+
+		st := d.state
+		st.mu.Lock()
+		if st.closed {
+			st.mu.Unlock()
+			return next(ctx, rec)
+		}
+
+		if e, ok := st.entries[fp]; ok {
+			e.count++
+			e.last = t
+			st.mu.Unlock()
+			return nil
+		}
+
+		var evicted *dedupEntry
+		if len(st.entries) >= d.opts.maxEntries && st.order.Len() > 0 {
+			evicted = st.order.Front().Value.(*dedupEntry)
+			evicted.timer.Stop()
+			st.remove(evicted)
+		}
+
+		e := &dedupEntry{
+			fp:    fp,
+			ctx:   context.WithoutCancel(ctx),
+			next:  next,
+			rec:   rec.Clone(),
+			count: 1,
+			first: t,
+			last:  t,
+		}
+		e.timer = time.AfterFunc(d.window, func() { d.expire(e) })
+		e.el = st.order.PushBack(e)
+		st.entries[fp] = e
+		st.mu.Unlock()
+
+		if evicted != nil {
+			if err := evicted.flush(); err != nil {
+				d.opts.onError(err)
+			}
+		}
+
+		return next(ctx, rec)
+	}
+}
+
+// WithAttrs adds the attributes to the scope of the derived Deduplicator
+// and passes them down the chain. All the attributes of the logger are
+// taken into account, regardless of DedupKeys.
+func (d *Deduplicator) WithAttrs(attrs []slog.Attr) (slogx.Interceptor, []slog.Attr) {
+	h := fnv.New64a()
+	writeUint64(h, d.scope)
+	for _, attr := range attrs {
+		d.hashAttr(h, d.groups, attr, true)
+	}
+
+	dd := d.derive()
+	dd.scope = h.Sum64()
+	return dd, attrs
+}
+
+// WithGroup adds the group to the scope of the derived Deduplicator
+// and passes it down the chain.
+func (d *Deduplicator) WithGroup(name string) (slogx.Interceptor, string) {
+	h := fnv.New64a()
+	writeUint64(h, d.scope)
+	_, _ = h.Write([]byte{1})
+	_, _ = h.Write([]byte(name))
+
+	dd := d.derive()
+	dd.scope = h.Sum64()
+	dd.groups = append(slices.Clip(d.groups), name)
+	return dd, name
+}
+
+func (d *Deduplicator) derive() *Deduplicator {
+	dd := *d
+	dd.wraps = &atomic.Uint64{}
+	return &dd
+}
+
+// Close passes the pending follow-up records down the chain and stops
+// deduplicating, records handled after Close are passed as is.
+func (d *Deduplicator) Close(context.Context) error {
+	st := d.state
+	st.mu.Lock()
+	st.closed = true
+	entries := st.entries
+	st.entries, st.order = map[uint64]*dedupEntry{}, list.New()
+	st.mu.Unlock()
+
+	var errs []error
+	for _, e := range entries {
+		e.timer.Stop()
+		if err := e.flush(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Deduplicator) expire(e *dedupEntry) {
+	st := d.state
+	st.mu.Lock()
+	if st.entries[e.fp] != e { // already flushed by Close or evicted
+		st.mu.Unlock()
+		return
+	}
+	st.remove(e)
+	st.mu.Unlock()
+
+	if err := e.flush(); err != nil {
+		d.opts.onError(err)
+	}
+}
+
+// remove removes the entry from the map and the order.
+// Must be called under the lock.
+func (st *dedupState) remove(e *dedupEntry) {
+	delete(st.entries, e.fp)
+	st.order.Remove(e.el)
+}
+
+// flush passes the follow-up record, if there were any repeats.
+// Must be called after the entry is removed from the map.
+func (e *dedupEntry) flush() error {
+	if e.count < 2 {
+		return nil
+	}
+
+	rec := e.rec.Clone()
+	rec.Time = e.last
+	rec.AddAttrs(
+		slog.Int(RepeatCountKey, e.count-1),
+		slog.Time(FirstSeenKey, e.first),
+		slog.Time(LastSeenKey, e.last),
+	)
+	return e.next(e.ctx, rec)
+}
+
+func (d *Deduplicator) fingerprint(composition uint64, rec slog.Record) uint64 {
+	h := fnv.New64a()
+	writeUint64(h, composition)
+	_, _ = h.Write([]byte(rec.Level.String()))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(rec.Message))
+
+	rec.Attrs(func(attr slog.Attr) bool {
+		d.hashAttr(h, d.groups, attr, false)
+		return true
+	})
+
+	return h.Sum64()
+}
+
+// hashAttr writes the attribute to the hash, if it is one of DedupKeys
+// or all is set.
+func (d *Deduplicator) hashAttr(h hash.Hash64, groups []string, attr slog.Attr, all bool) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			groups = append(slices.Clip(groups), attr.Key)
+		}
+		for _, a := range attr.Value.Group() {
+			d.hashAttr(h, groups, a, all)
+		}
+		return
+	}
+
+	key := attr.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + attr.Key
+	}
+
+	if d.opts.keys != nil && !all {
+		if _, ok := d.opts.keys[key]; !ok {
+			return
+		}
+	}
+
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{'='})
+	_, _ = h.Write([]byte(attr.Value.String()))
+}
+
+func hashUint64(a, b uint64) uint64 {
+	h := fnv.New64a()
+	writeUint64(h, a)
+	writeUint64(h, b)
+	return h.Sum64()
+}
+
+func writeUint64(h hash.Hash64, v uint64) {
+	_, _ = h.Write(binary.LittleEndian.AppendUint64(nil, v))
+}
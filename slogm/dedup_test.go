@@ -0,0 +1,167 @@
+package slogm
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/slogt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordsCollector struct {
+	mu   sync.Mutex
+	recs []slog.Record
+}
+
+func (c *recordsCollector) handle(_ context.Context, rec slog.Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.recs = append(c.recs, rec)
+	return nil
+}
+
+func (c *recordsCollector) records() []slog.Record {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]slog.Record(nil), c.recs...)
+}
+
+func TestDedup(t *testing.T) {
+	t.Run("repeats are collapsed after window", func(t *testing.T) {
+		c := &recordsCollector{}
+		dd := Dedup(50 * time.Millisecond)
+		lg := slog.New(slogx.NewChain(slogt.HandlerFunc(c.handle), dd.Wrap))
+
+		for i := 0; i < 5; i++ {
+			lg.Error("connection refused", slog.String("host", "db"))
+		}
+		lg.Error("connection refused", slog.String("host", "cache"))
+
+		require.Len(t, c.records(), 2)
+		require.Eventually(t, func() bool { return len(c.records()) == 3 }, time.Second, 5*time.Millisecond)
+
+		recs := c.records()
+		assert.Equal(t, "connection refused", recs[2].Message)
+		assert.Equal(t, slog.LevelError, recs[2].Level)
+
+		attrs := slogx.Attrs(recs[2])
+		require.Len(t, attrs, 4)
+		assert.Equal(t, slog.String("host", "db"), attrs[0])
+		assert.Equal(t, slog.Int(RepeatCountKey, 4), attrs[1])
+		assert.Equal(t, FirstSeenKey, attrs[2].Key)
+		assert.Equal(t, LastSeenKey, attrs[3].Key)
+		assert.False(t, attrs[3].Value.Time().Before(attrs[2].Value.Time()))
+
+		// window is closed - next record passes
+		lg.Error("connection refused", slog.String("host", "db"))
+		assert.Len(t, c.records(), 4)
+
+		require.NoError(t, dd.Close(context.Background()))
+		assert.Len(t, c.records(), 4, "no repeats - no follow-up record")
+	})
+
+	t.Run("subset of keys", func(t *testing.T) {
+		c := &recordsCollector{}
+		dd := Dedup(time.Hour, DedupKeys("req.method"))
+		lg := slog.New(slogx.NewChain(slogt.HandlerFunc(c.handle), dd.Wrap))
+
+		lg.Info("request", slog.Group("req", slog.String("method", "GET"), slog.String("id", "1")))
+		lg.Info("request", slog.Group("req", slog.String("method", "GET"), slog.String("id", "2")))
+		lg.Info("request", slog.Group("req", slog.String("method", "POST"), slog.String("id", "3")))
+		lg.Warn("request", slog.Group("req", slog.String("method", "POST"), slog.String("id", "4")))
+		require.Len(t, c.records(), 3)
+
+		require.NoError(t, dd.Close(context.Background()))
+		recs := c.records()
+		require.Len(t, recs, 4)
+		attrs := slogx.Attrs(recs[3])
+		assert.Equal(t, slog.Group("req", slog.String("method", "GET"), slog.String("id", "1")), attrs[0])
+		assert.Equal(t, slog.Int(RepeatCountKey, 1), attrs[1])
+
+		lg.Info("request", slog.Group("req", slog.String("method", "GET"), slog.String("id", "1")))
+		lg.Info("request", slog.Group("req", slog.String("method", "GET"), slog.String("id", "1")))
+		assert.Len(t, c.records(), 6, "closed deduplicator must pass records as is")
+	})
+
+	t.Run("max entries", func(t *testing.T) {
+		c := &recordsCollector{}
+		dd := Dedup(time.Hour, DedupMaxEntries(2))
+		lg := slog.New(slogx.NewChain(slogt.HandlerFunc(c.handle), dd.Wrap))
+
+		lg.Error("first")
+		lg.Error("first")
+		lg.Error("second")
+		assert.Len(t, dd.state.entries, 2)
+
+		// the oldest window is closed to track the new record
+		lg.Error("third")
+		assert.Len(t, dd.state.entries, 2)
+		recs := c.records()
+		require.Len(t, recs, 4)
+		assert.Equal(t, "first", recs[2].Message)
+		assert.Equal(t, slog.Int(RepeatCountKey, 1), slogx.Attrs(recs[2])[0])
+		assert.Equal(t, "third", recs[3].Message)
+
+		lg.Error("first")
+		assert.Len(t, c.records(), 5, "evicted fingerprint starts a new window")
+
+		require.NoError(t, dd.Close(context.Background()))
+		assert.Len(t, c.records(), 5)
+	})
+}
+
+func TestDedup_LoggerAttrs(t *testing.T) {
+	tenantRecords := func(recs []slog.Record) map[string][]int {
+		res := map[string][]int{}
+		for _, rec := range recs {
+			var tenant string
+			count := 1
+			rec.Attrs(func(a slog.Attr) bool {
+				switch a.Key {
+				case "tenant":
+					tenant = a.Value.String()
+				case RepeatCountKey:
+					count = int(a.Value.Int64()) + 1
+				}
+				return true
+			})
+			res[tenant] = append(res[tenant], count)
+		}
+		return res
+	}
+
+	t.Run("interceptor", func(t *testing.T) {
+		c := &recordsCollector{}
+		dd := Dedup(time.Hour)
+		lg := slog.New(slogx.NewChain(slogx.Accumulator(slogt.HandlerFunc(c.handle))).Use(dd))
+
+		lg.With("tenant", "a").Error("failed")
+		lg.With("tenant", "b").Error("failed")
+		lg.With("tenant", "b").Error("failed")
+		lg.With("tenant", "b").WithGroup("g").Error("failed")
+		require.Len(t, c.records(), 3)
+
+		require.NoError(t, dd.Close(context.Background()))
+		assert.Equal(t, map[string][]int{"a": {1}, "b": {1, 1, 2}}, tenantRecords(c.records()))
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		c := &recordsCollector{}
+		dd := Dedup(time.Hour)
+		lg := slog.New(slogx.NewChain(slogx.Accumulator(slogt.HandlerFunc(c.handle)), dd.Wrap))
+
+		a, b := lg.With("tenant", "a"), lg.With("tenant", "b")
+		a.Error("failed")
+		b.Error("failed")
+		b.Error("failed")
+		require.Len(t, c.records(), 2)
+
+		require.NoError(t, dd.Close(context.Background()))
+		assert.Equal(t, map[string][]int{"a": {1}, "b": {1, 2}}, tenantRecords(c.records()))
+	})
+}
@@ -0,0 +1,79 @@
+package slogm
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// ErrorAttrs returns a middleware that adds the attributes, attached with
+// slogx.WrapErr to the errors, logged as the record's attributes, to the record.
+// The record's own attributes win over the ones of errors with the same keys,
+// as well as the errors, logged earlier, win over the later ones.
+// Errors, logged with slogx.Error, already have their attributes inlined,
+// so they are not duplicated.
+func ErrorAttrs() slogx.Middleware {
+	return func(next slogx.HandleFunc) slogx.HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error {
+			var errs []error
+			rec.Attrs(func(attr slog.Attr) bool {
+				errs = appendErrors(errs, attr)
+				return true
+			})
+			if len(errs) == 0 {
+				return next(ctx, rec)
+			}
+
+			keys := map[string]struct{}{}
+			rec.Attrs(func(attr slog.Attr) bool {
+				addKeys(keys, attr)
+				return true
+			})
+
+			var attrs []slog.Attr
+			for _, err := range errs {
+				for _, attr := range slogx.ErrorAttrs(err) {
+					if _, ok := keys[attr.Key]; ok {
+						continue
+					}
+					keys[attr.Key] = struct{}{}
+					attrs = append(attrs, attr)
+				}
+			}
+
+			rec.AddAttrs(attrs...)
+			return next(ctx, rec)
+		}
+	}
+}
+
+// appendErrors appends the error-valued attributes, including the ones
+// of the inlined groups, to the errs.
+func appendErrors(errs []error, attr slog.Attr) []error {
+	switch attr.Value.Kind() {
+	case slog.KindGroup:
+		if attr.Key != "" {
+			return errs
+		}
+		for _, a := range attr.Value.Group() {
+			errs = appendErrors(errs, a)
+		}
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok && err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// addKeys adds the top-level keys of the attribute to the set.
+func addKeys(keys map[string]struct{}, attr slog.Attr) {
+	if attr.Key == "" && attr.Value.Kind() == slog.KindGroup {
+		for _, a := range attr.Value.Group() {
+			addKeys(keys, a)
+		}
+		return
+	}
+	keys[attr.Key] = struct{}{}
+}
@@ -0,0 +1,72 @@
+package slogm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorAttrs(t *testing.T) {
+	handle := func(t *testing.T, attrs ...slog.Attr) []slog.Attr {
+		t.Helper()
+
+		var res []slog.Attr
+		h := ErrorAttrs()(func(_ context.Context, rec slog.Record) error {
+			res = slogx.Attrs(rec)
+			return nil
+		})
+
+		rec := slog.NewRecord(time.Now(), slog.LevelError, "failed", 0)
+		rec.AddAttrs(attrs...)
+		require.NoError(t, h(context.Background(), rec))
+		return res
+	}
+
+	t.Run("extracts attributes", func(t *testing.T) {
+		err := fmt.Errorf("read: %w", slogx.WrapErr(io.EOF, slog.String("path", "config.yaml")))
+		assert.Equal(t, []slog.Attr{
+			slog.Any("err", err),
+			slog.String("path", "config.yaml"),
+		}, handle(t, slog.Any("err", err)))
+	})
+
+	t.Run("record wins", func(t *testing.T) {
+		err := slogx.WrapErr(io.EOF, slog.String("user_id", "from error"), slog.Int("status", 500))
+		assert.Equal(t, []slog.Attr{
+			slog.String("user_id", "from record"),
+			slog.Any("err", err),
+			slog.Int("status", 500),
+		}, handle(t, slog.String("user_id", "from record"), slog.Any("err", err)))
+	})
+
+	t.Run("earlier error wins", func(t *testing.T) {
+		err1 := slogx.WrapErr(io.EOF, slog.String("a", "1"))
+		err2 := slogx.WrapErr(io.ErrUnexpectedEOF, slog.String("a", "2"), slog.String("b", "2"))
+		assert.Equal(t, []slog.Attr{
+			slog.Any("err1", err1),
+			slog.Any("err2", err2),
+			slog.String("a", "1"),
+			slog.String("b", "2"),
+		}, handle(t, slog.Any("err1", err1), slog.Any("err2", err2)))
+	})
+
+	t.Run("logged with slogx.Error", func(t *testing.T) {
+		err := slogx.WrapErr(io.EOF, slog.String("path", "config.yaml"))
+		attr := slogx.Error(err)
+		assert.Equal(t, []slog.Attr{attr}, handle(t, attr))
+	})
+
+	t.Run("no errors", func(t *testing.T) {
+		assert.Equal(t, []slog.Attr{slog.String("a", "b")}, handle(t, slog.String("a", "b")))
+		assert.Equal(t, []slog.Attr{slog.Group("g", slog.Any("err", errors.New("x")))},
+			handle(t, slog.Group("g", slog.Any("err", errors.New("x")))))
+	})
+}
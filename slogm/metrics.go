@@ -0,0 +1,247 @@
+package slogm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/internal/promname"
+)
+
+// MetricsOverflowValue is the value of the labels of the records, counted
+// after the amount of series reached the limit.
+const MetricsOverflowValue = "other"
+
+type metricsLabel struct {
+	name  string
+	keyFn KeyFunc
+}
+
+type metricsOptions struct {
+	namespace string
+	labels    []metricsLabel
+	maxSeries int
+}
+
+// MetricsOption is a functional option for Metrics.
+type MetricsOption func(*metricsOptions)
+
+// MetricsNamespace sets the prefix of the names of the metrics.
+// Default is "slog", i.e. the metrics are "slog_records_total" and
+// "slog_handler_errors_total".
+func MetricsNamespace(ns string) MetricsOption { return func(o *metricsOptions) { o.namespace = ns } }
+
+// MetricsLabel adds the label to count the records by, in addition to their
+// level, e.g. MetricsLabel("service", KeyAttr("service")) or
+// MetricsLabel("msg", KeyMessage). The name, that collides with "level" or
+// with the name of another label, is prefixed with "exported_", e.g.
+// MetricsLabel("level", ...) is exposed as "exported_level".
+func MetricsLabel(name string, fn KeyFunc) MetricsOption {
+	return func(o *metricsOptions) { o.labels = append(o.labels, metricsLabel{name: name, keyFn: fn}) }
+}
+
+// MetricsMaxSeries sets the maximum amount of the label combinations to count
+// the records by. Once it is reached, records with the new combinations are
+// counted with MetricsOverflowValue in all the labels, but the level.
+// Default is 1000.
+func MetricsMaxSeries(n int) MetricsOption { return func(o *metricsOptions) { o.maxSeries = n } }
+
+// MetricsCollector is a middleware that counts the records by their level
+// and the labels, set with MetricsLabel, and the errors, returned by the rest
+// of the chain for them.
+//
+// The counters are exposed in the Prometheus text format by ServeHTTP and
+// as JSON by String, so the collector may be published with expvar.Publish.
+type MetricsCollector struct {
+	opts  metricsOptions
+	names []string // names of the level and the labels, sanitized for Prometheus
+
+	mu     sync.RWMutex
+	series map[string]*metricsSeries
+}
+
+type metricsSeries struct {
+	key     string
+	labels  []string // values of the level and the labels, set with MetricsLabel
+	records atomic.Uint64
+	errors  atomic.Uint64
+}
+
+// Metrics makes a new MetricsCollector.
+func Metrics(opts ...MetricsOption) *MetricsCollector {
+	o := metricsOptions{namespace: "slog", maxSeries: 1000}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	names := make([]string, 0, len(o.labels)+1)
+	names = append(names, slog.LevelKey)
+	for _, l := range o.labels {
+		name := promname.Sanitize(l.name)
+		for slices.Contains(names, name) {
+			name = "exported_" + name
+		}
+		names = append(names, name)
+	}
+
+	return &MetricsCollector{opts: o, names: names, series: map[string]*metricsSeries{}}
+}
+
+// Wrap is a slogx.Middleware that counts records and errors.
+func (m *MetricsCollector) Wrap(next slogx.HandleFunc) slogx.HandleFunc {
+	return func(ctx context.Context, rec slog.Record) error {
+		s := m.get(ctx, rec)
+		s.records.Add(1)
+
+		err := next(ctx, rec)
+		if err != nil {
+			s.errors.Add(1)
+		}
+		return err
+	}
+}
+
+// get returns the series of the record, creating it, if needed.
+func (m *MetricsCollector) get(ctx context.Context, rec slog.Record) *metricsSeries {
+	labels := make([]string, 0, len(m.opts.labels)+1)
+	labels = append(labels, strings.ToLower(slogx.LevelName(rec.Level)))
+	for _, l := range m.opts.labels {
+		labels = append(labels, l.keyFn(ctx, rec))
+	}
+	key := strings.Join(labels, "\xff")
+
+	m.mu.RLock()
+	s, ok := m.series[key]
+	m.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s, ok = m.series[key]; ok {
+		return s
+	}
+
+	if len(m.series) >= m.opts.maxSeries && len(m.opts.labels) > 0 {
+		for i := 1; i < len(labels); i++ {
+			labels[i] = MetricsOverflowValue
+		}
+		key = strings.Join(labels, "\xff")
+		if s, ok = m.series[key]; ok {
+			return s
+		}
+	}
+
+	s = &metricsSeries{key: key, labels: labels}
+	m.series[key] = s
+	return s
+}
+
+// snapshot returns the series, sorted by their labels.
+func (m *MetricsCollector) snapshot() []*metricsSeries {
+	m.mu.RLock()
+	res := make([]*metricsSeries, 0, len(m.series))
+	for _, s := range m.series {
+		res = append(res, s)
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool { return res[i].key < res[j].key })
+	return res
+}
+
+// ServeHTTP writes the counters in the Prometheus text exposition format.
+func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.WritePrometheus(w)
+}
+
+// WritePrometheus writes the counters in the Prometheus text exposition format.
+func (m *MetricsCollector) WritePrometheus(w io.Writer) error {
+	var sb strings.Builder
+
+	names, series := m.names, m.snapshot()
+	ns := promname.Sanitize(m.opts.namespace)
+
+	for _, metric := range []struct {
+		name, help string
+		value      func(s *metricsSeries) uint64
+	}{
+		{
+			name:  ns + "_records_total",
+			help:  "Total number of log records.",
+			value: func(s *metricsSeries) uint64 { return s.records.Load() },
+		},
+		{
+			name:  ns + "_handler_errors_total",
+			help:  "Total number of errors, returned by the log handler.",
+			value: func(s *metricsSeries) uint64 { return s.errors.Load() },
+		},
+	} {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for _, s := range series {
+			sb.WriteString(metric.name)
+			sb.WriteByte('{')
+			for i, v := range s.labels {
+				if i > 0 {
+					sb.WriteByte(',')
+				}
+				fmt.Fprintf(&sb, "%s=\"%s\"", names[i], escapeLabelValue(v))
+			}
+			fmt.Fprintf(&sb, "} %d\n", metric.value(s))
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("write metrics: %w", err)
+	}
+	return nil
+}
+
+// String returns the counters as JSON, it implements expvar.Var, e.g.
+//
+//	{"series":[{"labels":{"level":"error"},"records":3,"errors":1}]}
+func (m *MetricsCollector) String() string {
+	type jsonSeries struct {
+		Labels  map[string]string `json:"labels"`
+		Records uint64            `json:"records"`
+		Errors  uint64            `json:"errors"`
+	}
+
+	names, series := m.names, m.snapshot()
+
+	res := struct {
+		Series []jsonSeries `json:"series"`
+	}{Series: make([]jsonSeries, len(series))}
+	for i, s := range series {
+		labels := make(map[string]string, len(s.labels))
+		for j, v := range s.labels {
+			labels[names[j]] = v
+		}
+		res.Series[i] = jsonSeries{Labels: labels, Records: s.records.Load(), Errors: s.errors.Load()}
+	}
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes the value of the label for the text format.
+func escapeLabelValue(v string) string {
+	return labelValueReplacer.Replace(strings.ToValidUTF8(v, "\uFFFD"))
+}
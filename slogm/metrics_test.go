@@ -0,0 +1,131 @@
+package slogm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := Metrics(MetricsLabel("service", KeyAttr("service")))
+	h := m.Wrap(func(_ context.Context, rec slog.Record) error {
+		if rec.Level >= slog.LevelError {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	handle := func(lvl slog.Level, attrs ...slog.Attr) error {
+		rec := slog.NewRecord(time.Now(), lvl, "message", 0)
+		rec.AddAttrs(attrs...)
+		return h(context.Background(), rec)
+	}
+
+	require.NoError(t, handle(slog.LevelInfo, slog.String("service", "api")))
+	require.NoError(t, handle(slog.LevelInfo, slog.String("service", "api")))
+	require.NoError(t, handle(slogx.LevelNotice, slog.String("service", "with \"quotes\"\n")))
+	require.NoError(t, handle(slog.LevelWarn))
+	assert.EqualError(t, handle(slog.LevelError, slog.String("service", "api")), "handler failed")
+
+	t.Run("prometheus", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+
+		assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Equal(t, `# HELP slog_records_total Total number of log records.
+# TYPE slog_records_total counter
+slog_records_total{level="error",service="api"} 1
+slog_records_total{level="info",service="api"} 2
+slog_records_total{level="notice",service="with \"quotes\"\n"} 1
+slog_records_total{level="warn",service=""} 1
+# HELP slog_handler_errors_total Total number of errors, returned by the log handler.
+# TYPE slog_handler_errors_total counter
+slog_handler_errors_total{level="error",service="api"} 1
+slog_handler_errors_total{level="info",service="api"} 0
+slog_handler_errors_total{level="notice",service="with \"quotes\"\n"} 0
+slog_handler_errors_total{level="warn",service=""} 0
+`, rr.Body.String())
+	})
+
+	t.Run("expvar", func(t *testing.T) {
+		var v expvar.Var = m
+
+		var res struct {
+			Series []struct {
+				Labels  map[string]string `json:"labels"`
+				Records uint64            `json:"records"`
+				Errors  uint64            `json:"errors"`
+			} `json:"series"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(v.String()), &res))
+		require.Len(t, res.Series, 4)
+		assert.Equal(t, map[string]string{"level": "error", "service": "api"}, res.Series[0].Labels)
+		assert.Equal(t, uint64(1), res.Series[0].Records)
+		assert.Equal(t, uint64(1), res.Series[0].Errors)
+		assert.Equal(t, map[string]string{"level": "info", "service": "api"}, res.Series[1].Labels)
+		assert.Equal(t, uint64(2), res.Series[1].Records)
+		assert.Equal(t, uint64(0), res.Series[1].Errors)
+	})
+}
+
+func TestMetrics_MaxSeries(t *testing.T) {
+	m := Metrics(MetricsNamespace("app-logs"), MetricsLabel("msg", KeyMessage), MetricsMaxSeries(2))
+	h := m.Wrap(func(context.Context, slog.Record) error { return nil })
+
+	for _, msg := range []string{"first", "second", "third", "fourth", "first"} {
+		require.NoError(t, h(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0)))
+	}
+
+	var buf strings.Builder
+	require.NoError(t, m.WritePrometheus(&buf))
+	assert.Contains(t, buf.String(), `app_logs_records_total{level="info",msg="first"} 2
+app_logs_records_total{level="info",msg="other"} 2
+app_logs_records_total{level="info",msg="second"} 1
+`)
+}
+
+func TestMetrics_LabelCollisions(t *testing.T) {
+	m := Metrics(MetricsLabel("level", KeyAttr("level")), MetricsLabel("exported.level", KeyMessage))
+	h := m.Wrap(func(context.Context, slog.Record) error { return nil })
+
+	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+	rec.AddAttrs(slog.String("level", "custom"))
+	require.NoError(t, h(context.Background(), rec))
+
+	var buf strings.Builder
+	require.NoError(t, m.WritePrometheus(&buf))
+	assert.Contains(t, buf.String(),
+		`slog_records_total{level="info",exported_level="custom",exported_exported_level="message"} 1`)
+}
+
+func TestMetrics_Concurrent(t *testing.T) {
+	m := Metrics(MetricsLabel("msg", KeyMessage))
+	h := m.Wrap(func(context.Context, slog.Record) error { return nil })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Go(func() {
+			_ = h(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, fmt.Sprintf("msg %d", i%10), 0))
+			_ = m.String()
+		})
+	}
+	wg.Wait()
+
+	var buf strings.Builder
+	require.NoError(t, m.WritePrometheus(&buf))
+	assert.Equal(t, 10+10+4, strings.Count(buf.String(), "\n"))
+	assert.Contains(t, buf.String(), `slog_records_total{level="info",msg="msg 7"} 10`)
+}
@@ -0,0 +1,252 @@
+package slogm
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// KeyAttr returns a KeyFunc that groups records by the value of the
+// top-level attribute with the given key.
+func KeyAttr(key string) KeyFunc {
+	return func(_ context.Context, rec slog.Record) (res string) {
+		rec.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == key {
+				res = attr.Value.String()
+				return false
+			}
+			return true
+		})
+		return res
+	}
+}
+
+// KeySource is a KeyFunc that groups records by the place they were logged at,
+// in the "file:line" format.
+func KeySource(_ context.Context, rec slog.Record) string {
+	if rec.PC == 0 {
+		return ""
+	}
+	f, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// KeyRequestID is a KeyFunc that groups records by the request id from the context.
+func KeyRequestID(ctx context.Context, _ slog.Record) string {
+	reqID, _ := RequestIDFromContext(ctx)
+	return reqID
+}
+
+// RateLimitOverflowKey is the key of the attribute, set to true instead of
+// the "key" attribute in the report of the suppressed records of the evicted
+// keys, once there are more than RateLimitMaxKeys of them within the report
+// interval, so they are never merged with the records of a real key.
+const RateLimitOverflowKey = "other_keys"
+
+type rateLimitOptions struct {
+	keyFn   KeyFunc
+	maxKeys int
+	report  time.Duration
+}
+
+// RateLimitOption is a functional option for RateLimit.
+type RateLimitOption func(*rateLimitOptions)
+
+// RateLimitKey sets the function to group records by.
+// By default, records are grouped by their level and message.
+func RateLimitKey(fn KeyFunc) RateLimitOption { return func(o *rateLimitOptions) { o.keyFn = fn } }
+
+// RateLimitMaxKeys sets the maximum amount of keys to keep the buckets for,
+// the least recently used buckets are evicted. Default is 1024.
+func RateLimitMaxKeys(n int) RateLimitOption { return func(o *rateLimitOptions) { o.maxKeys = n } }
+
+// RateLimitReport sets the interval to report the amount of suppressed records.
+// Default is one minute, zero disables the reports.
+func RateLimitReport(d time.Duration) RateLimitOption {
+	return func(o *rateLimitOptions) { o.report = d }
+}
+
+// RateLimit returns a middleware that limits the amount of records with the
+// same key to limit per second, allowing bursts of up to burst records.
+// Records above the limit are dropped.
+//
+// Once in a report interval, for every key with suppressed records, the
+// middleware passes down the chain a separate warning record with the key
+// and the amount of suppressed records. Reports are checked for on handling
+// of the records, so there is no background goroutine.
+func RateLimit(limit float64, burst int, opts ...RateLimitOption) slogx.Middleware {
+	o := rateLimitOptions{keyFn: KeyLevelMessage, maxKeys: 1024, report: time.Minute}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	rl := &rateLimiter{
+		opts:    o,
+		limit:   limit,
+		burst:   float64(burst),
+		lru:     list.New(),
+		buckets: map[string]*list.Element{},
+		evicted: map[string]uint64{},
+	}
+
+	return func(next slogx.HandleFunc) slogx.HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error {
+			t := rec.Time
+			if t.IsZero() {
+				t = time.Now()
+			}
+
+			allowed := rl.allow(o.keyFn(ctx, rec), t)
+
+			var errs []error
+			for _, s := range rl.suppressed(t) {
+				if err := next(ctx, s.record(t)); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if allowed {
+				if err := next(ctx, rec); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			return errors.Join(errs...)
+		}
+	}
+}
+
+type rateLimiter struct {
+	opts  rateLimitOptions
+	limit float64
+	burst float64
+
+	mu           sync.Mutex
+	lru          *list.List // of *bucket, most recently used first
+	buckets      map[string]*list.Element
+	evicted      map[string]uint64 // suppressed records of evicted buckets, up to maxKeys
+	evictedOther uint64            // suppressed records of evicted buckets above maxKeys
+	lastReport   time.Time
+}
+
+type bucket struct {
+	key        string
+	tokens     float64
+	last       time.Time
+	suppressed uint64
+}
+
+func (rl *rateLimiter) allow(key string, t time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	var b *bucket
+	if el, ok := rl.buckets[key]; ok {
+		rl.lru.MoveToFront(el)
+		b = el.Value.(*bucket)
+	} else {
+		b = &bucket{key: key, tokens: rl.burst, last: t}
+		rl.buckets[key] = rl.lru.PushFront(b)
+		rl.evict()
+	}
+
+	if elapsed := t.Sub(b.last); elapsed > 0 {
+		b.tokens = min(rl.burst, b.tokens+elapsed.Seconds()*rl.limit)
+		b.last = t
+	}
+
+	if b.tokens < 1 {
+		b.suppressed++
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// evict removes the least recently used buckets above the limit.
+func (rl *rateLimiter) evict() {
+	for rl.lru.Len() > rl.opts.maxKeys {
+		b := rl.lru.Remove(rl.lru.Back()).(*bucket)
+		delete(rl.buckets, b.key)
+		if b.suppressed == 0 || rl.opts.report <= 0 {
+			continue
+		}
+
+		// evicted counters are capped as well, the rest are summed up
+		if _, ok := rl.evicted[b.key]; ok || len(rl.evicted) < rl.opts.maxKeys {
+			rl.evicted[b.key] += b.suppressed
+		} else {
+			rl.evictedOther += b.suppressed
+		}
+	}
+}
+
+type suppressedKey struct {
+	key      string
+	overflow bool // whether the records of the evicted keys above the limit are reported
+	n        uint64
+}
+
+func (s suppressedKey) record(t time.Time) slog.Record {
+	// the report is not logged from any place in the code
+	rec := slog.NewRecord(t, slog.LevelWarn, "[slogm.RateLimit] records suppressed", 0)
+	if s.overflow {
+		rec.AddAttrs(slog.Bool(RateLimitOverflowKey, true), slog.Uint64("suppressed", s.n))
+		return rec
+	}
+	rec.AddAttrs(slog.String("key", s.key), slog.Uint64("suppressed", s.n))
+	return rec
+}
+
+// suppressed returns the keys with suppressed records and resets their
+// counters, if the report interval has passed.
+func (rl *rateLimiter) suppressed(t time.Time) []suppressedKey {
+	if rl.opts.report <= 0 {
+		return nil
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.lastReport.IsZero() {
+		rl.lastReport = t
+		return nil
+	}
+
+	if t.Sub(rl.lastReport) < rl.opts.report {
+		return nil
+	}
+	rl.lastReport = t
+
+	var res []suppressedKey
+	for el := rl.lru.Front(); el != nil; el = el.Next() {
+		b := el.Value.(*bucket)
+		if b.suppressed > 0 {
+			res = append(res, suppressedKey{key: b.key, n: b.suppressed})
+			b.suppressed = 0
+		}
+	}
+
+	for key, n := range rl.evicted {
+		res = append(res, suppressedKey{key: key, n: n})
+		delete(rl.evicted, key)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].key < res[j].key })
+
+	if rl.evictedOther > 0 {
+		res = append(res, suppressedKey{overflow: true, n: rl.evictedOther})
+		rl.evictedOther = 0
+	}
+
+	return res
+}
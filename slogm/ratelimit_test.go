@@ -0,0 +1,129 @@
+package slogm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	collect := func(mw slogx.Middleware) (slogx.HandleFunc, *[]slog.Record) {
+		var recs []slog.Record
+		return mw(func(_ context.Context, rec slog.Record) error {
+			recs = append(recs, rec)
+			return nil
+		}), &recs
+	}
+
+	t.Run("burst and refill", func(t *testing.T) {
+		h, recs := collect(RateLimit(1, 2, RateLimitReport(0)))
+
+		for _, tm := range []time.Time{
+			ts, ts, ts, // burst of 2, third is dropped
+			ts.Add(500 * time.Millisecond), // half of a token refilled - dropped
+			ts.Add(time.Second),            // token refilled
+			ts.Add(time.Second),            // dropped
+		} {
+			require.NoError(t, h(context.Background(), slog.NewRecord(tm, slog.LevelInfo, "msg", 0)))
+		}
+
+		assert.Len(t, *recs, 3)
+	})
+
+	t.Run("per key", func(t *testing.T) {
+		h, recs := collect(RateLimit(1, 1, RateLimitKey(KeyAttr("tenant")), RateLimitReport(0)))
+
+		for _, tenant := range []string{"a", "a", "b", "b", "c"} {
+			rec := slog.NewRecord(ts, slog.LevelInfo, tenant, 0)
+			rec.AddAttrs(slog.String("tenant", tenant))
+			require.NoError(t, h(context.Background(), rec))
+		}
+
+		var msgs []string
+		for _, rec := range *recs {
+			msgs = append(msgs, rec.Message)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, msgs)
+	})
+
+	t.Run("report suppressed", func(t *testing.T) {
+		h, recs := collect(RateLimit(1, 1,
+			RateLimitKey(KeyRequestID),
+			RateLimitReport(time.Minute),
+			RateLimitMaxKeys(1),
+		))
+
+		ctxA := ContextWithRequestID(context.Background(), "a")
+		ctxB := ContextWithRequestID(context.Background(), "b")
+		for _, ctx := range []context.Context{ctxA, ctxA, ctxA, ctxB, ctxB} {
+			require.NoError(t, h(ctx, slog.NewRecord(ts, slog.LevelInfo, "msg", 0)))
+		}
+		require.Len(t, *recs, 2)
+
+		require.NoError(t, h(ctxB, slog.NewRecord(ts.Add(time.Minute), slog.LevelInfo, "msg", 0)))
+		require.Len(t, *recs, 5)
+
+		assert.Equal(t, "[slogm.RateLimit] records suppressed", (*recs)[2].Message)
+		assert.Equal(t, slog.LevelWarn, (*recs)[2].Level)
+		assert.Equal(t, []slog.Attr{slog.String("key", "a"), slog.Uint64("suppressed", 2)},
+			slogx.Attrs((*recs)[2]), "must be reported after eviction")
+		assert.Equal(t, []slog.Attr{slog.String("key", "b"), slog.Uint64("suppressed", 1)},
+			slogx.Attrs((*recs)[3]))
+		assert.Equal(t, "msg", (*recs)[4].Message)
+	})
+
+	t.Run("evicted keys are capped", func(t *testing.T) {
+		h, recs := collect(RateLimit(1, 1, RateLimitKey(KeyRequestID), RateLimitMaxKeys(2)))
+
+		for i := 0; i < 100; i++ {
+			id := fmt.Sprintf("%03d", i)
+			if i == 50 {
+				id = "other" // must not be merged with the overflow
+			}
+			ctx := ContextWithRequestID(context.Background(), id)
+			for j := 0; j < 3; j++ {
+				require.NoError(t, h(ctx, slog.NewRecord(ts, slog.LevelInfo, "msg", 0)))
+			}
+		}
+		require.Len(t, *recs, 100)
+
+		require.NoError(t, h(context.Background(), slog.NewRecord(ts.Add(time.Minute), slog.LevelInfo, "msg", 0)))
+		reports := map[string]uint64{}
+		for _, rec := range (*recs)[100 : len(*recs)-2] {
+			assert.Equal(t, uintptr(0), rec.PC)
+			attrs := slogx.Attrs(rec)
+			reports[attrs[0].Value.String()] = attrs[1].Value.Uint64()
+		}
+		assert.Equal(t, map[string]uint64{
+			"000": 2, "001": 2, // evicted, within the cap
+			"099": 2, // still in the buckets, "098" is evicted by the last record
+		}, reports)
+
+		overflow := (*recs)[len(*recs)-2]
+		assert.Equal(t, []slog.Attr{slog.Bool(RateLimitOverflowKey, true), slog.Uint64("suppressed", 97*2)},
+			slogx.Attrs(overflow), "evicted keys above the cap, including \"other\"")
+	})
+
+	t.Run("by source", func(t *testing.T) {
+		h, recs := collect(RateLimit(1, 1, RateLimitKey(KeySource), RateLimitReport(0)))
+
+		for i := 0; i < 2; i++ {
+			var pcs [1]uintptr
+			runtime.Callers(1, pcs[:])
+			require.NoError(t, h(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "first", pcs[0])))
+
+			runtime.Callers(1, pcs[:])
+			require.NoError(t, h(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "second", pcs[0])))
+		}
+		assert.Len(t, *recs, 2)
+	})
+}
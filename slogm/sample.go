@@ -0,0 +1,133 @@
+package slogm
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// KeyFunc returns the key of the record, by which records are grouped
+// by the middlewares, e.g. for sampling.
+type KeyFunc func(context.Context, slog.Record) string
+
+// KeyLevelMessage is a KeyFunc that groups records by their level and message.
+func KeyLevelMessage(_ context.Context, rec slog.Record) string {
+	return rec.Level.String() + ":" + rec.Message
+}
+
+// KeyMessage is a KeyFunc that groups records by their message.
+func KeyMessage(_ context.Context, rec slog.Record) string { return rec.Message }
+
+const sampleCounters = 4096
+
+type sampleOptions struct {
+	tick      time.Duration
+	keyFn     KeyFunc
+	reportKey string
+}
+
+// SampleOption is a functional option for Sample.
+type SampleOption func(*sampleOptions)
+
+// SampleTick sets the interval, after which the counters are reset.
+// Default is one second.
+func SampleTick(d time.Duration) SampleOption { return func(o *sampleOptions) { o.tick = d } }
+
+// SampleKey sets the function to group records by.
+// By default, records are grouped by their level and message.
+func SampleKey(fn KeyFunc) SampleOption { return func(o *sampleOptions) { o.keyFn = fn } }
+
+// SampleReportSuppressed makes the middleware to add the amount of records
+// suppressed since the last passed one with the same key, under the given
+// attribute key.
+func SampleReportSuppressed(key string) SampleOption {
+	return func(o *sampleOptions) { o.reportKey = key }
+}
+
+// Sample returns a middleware that passes the first records with the same key
+// per tick and then every thereafter-th record, the rest of them are dropped.
+// If thereafter is zero, all records after the first ones are dropped until the
+// end of the tick.
+//
+// Counters are kept in a fixed-size table without locks, keys are hashed into it,
+// so records with different keys may rarely share the same counter.
+func Sample(first, thereafter uint64, opts ...SampleOption) slogx.Middleware {
+	o := sampleOptions{tick: time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	counters := &[sampleCounters]sampleCounter{}
+
+	return func(next slogx.HandleFunc) slogx.HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error {
+			var h uint32
+			if o.keyFn != nil {
+				h = fnv32a(fnvOffset32, o.keyFn(ctx, rec))
+			} else {
+				h = fnv32a(fnv32a(fnvOffset32, rec.Level.String()), rec.Message)
+			}
+			c := &counters[h%sampleCounters]
+
+			t := rec.Time
+			if t.IsZero() {
+				t = time.Now()
+			}
+
+			n := c.inc(t, o.tick)
+			if n > first && (thereafter == 0 || (n-first)%thereafter != 0) {
+				c.suppressed.Add(1)
+				return nil
+			}
+
+			if o.reportKey != "" {
+				if s := c.suppressed.Swap(0); s > 0 {
+					rec.AddAttrs(slog.Uint64(o.reportKey, s))
+				}
+			}
+
+			return next(ctx, rec)
+		}
+	}
+}
+
+type sampleCounter struct {
+	resetAt    atomic.Int64
+	counter    atomic.Uint64
+	suppressed atomic.Uint64
+}
+
+// inc increments the counter and returns its new value,
+// resetting it, if the tick has passed.
+func (c *sampleCounter) inc(t time.Time, tick time.Duration) uint64 {
+	tn := t.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > tn {
+		return c.counter.Add(1)
+	}
+
+	c.counter.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, tn+tick.Nanoseconds()) {
+		// someone else has reset the counter
+		return c.counter.Add(1)
+	}
+	return 1
+}
+
+const (
+	fnvOffset32 = 2166136261
+	fnvPrime32  = 16777619
+)
+
+// fnv32a continues the FNV-1a hash h with the bytes of s,
+// without allocating a hash.Hash.
+func fnv32a(h uint32, s string) uint32 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= fnvPrime32
+	}
+	return h
+}
@@ -0,0 +1,101 @@
+package slogm
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/slogt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSample(t *testing.T) {
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("first and thereafter", func(t *testing.T) {
+		var passed []slog.Record
+		h := Sample(2, 3, SampleReportSuppressed("suppressed"))(func(_ context.Context, rec slog.Record) error {
+			passed = append(passed, rec)
+			return nil
+		})
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, h(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "msg", 0)))
+		}
+		require.NoError(t, h(context.Background(), slog.NewRecord(ts, slog.LevelWarn, "msg", 0)))
+
+		require.Len(t, passed, 5)
+		assert.Empty(t, slogx.Attrs(passed[0]))
+		assert.Empty(t, slogx.Attrs(passed[1]))
+		assert.Equal(t, []slog.Attr{slog.Uint64("suppressed", 2)}, slogx.Attrs(passed[2]))
+		assert.Equal(t, []slog.Attr{slog.Uint64("suppressed", 2)}, slogx.Attrs(passed[3]))
+		assert.Equal(t, slog.LevelWarn, passed[4].Level, "different level must be sampled separately")
+		assert.Empty(t, slogx.Attrs(passed[4]))
+	})
+
+	t.Run("counter is reset after tick", func(t *testing.T) {
+		passed := 0
+		h := Sample(1, 0, SampleTick(time.Minute))(func(context.Context, slog.Record) error {
+			passed++
+			return nil
+		})
+
+		for _, tm := range []time.Time{ts, ts.Add(time.Second), ts.Add(time.Minute), ts.Add(time.Minute + time.Second)} {
+			require.NoError(t, h(context.Background(), slog.NewRecord(tm, slog.LevelInfo, "msg", 0)))
+		}
+		assert.Equal(t, 2, passed)
+	})
+
+	t.Run("custom key", func(t *testing.T) {
+		var passed []string
+		h := Sample(1, 0, SampleKey(func(_ context.Context, rec slog.Record) string {
+			key := ""
+			rec.Attrs(func(attr slog.Attr) bool {
+				if attr.Key == "user" {
+					key = attr.Value.String()
+					return false
+				}
+				return true
+			})
+			return key
+		}))(func(_ context.Context, rec slog.Record) error {
+			passed = append(passed, rec.Message)
+			return nil
+		})
+
+		for i, user := range []string{"a", "b", "a", "b", "c"} {
+			rec := slog.NewRecord(ts, slog.LevelInfo, string(rune('0'+i)), 0)
+			rec.AddAttrs(slog.String("user", user))
+			require.NoError(t, h(context.Background(), rec))
+		}
+		assert.Equal(t, []string{"0", "1", "4"}, passed)
+	})
+
+	t.Run("in chain", func(t *testing.T) {
+		var passed atomic.Int64
+		h := slogx.NewChain(slogt.HandlerFunc(func(context.Context, slog.Record) error {
+			passed.Add(1)
+			return nil
+		}), Sample(10, 100))
+
+		wg := &sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					_ = h.Handle(context.Background(), slog.NewRecord(ts, slog.LevelInfo, "msg", 0))
+				}
+			}()
+		}
+		wg.Wait()
+
+		// 10 first, and then every 100th of the remaining 7990
+		assert.Equal(t, int64(10+79), passed.Load())
+	})
+}
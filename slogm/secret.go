@@ -51,7 +51,8 @@ func SecretsFromContext(ctx context.Context) ([]string, bool) {
 	return v.Get(), true
 }
 
-// MaskSecrets is a middleware that masks secrets (retrieved from context) in logs.
+// MaskSecrets is a middleware that masks secrets (retrieved from context) in logs,
+// i.e. in the message and the attributes, including the ones inside groups.
 //
 // Works only with attributes of type String/[]byte or Any.
 // If attribute is of type Any, there will be attempt to match it to:
@@ -72,39 +73,28 @@ func MaskSecrets(replacement string) slogx.Middleware {
 				return next(ctx, rec)
 			}
 
-			var nattrs []slog.Attr
-			hasMaskedAttrs := false
-			rec.Attrs(func(attr slog.Attr) bool {
-				nattr, trimmed := maskAttr(secrets, replacement, attr)
-				nattrs = append(nattrs, nattr)
-				hasMaskedAttrs = hasMaskedAttrs || trimmed
-				return true
+			rec = slogx.MapAttrs(rec, func(_ []string, attr slog.Attr) slog.Attr {
+				return maskAttr(secrets, replacement, attr)
 			})
+			rec.Message, _ = mask(secrets, replacement, rec.Message)
 
-			msg, hasMaskedMessage := mask(secrets, replacement, rec.Message)
-
-			if !hasMaskedAttrs && !hasMaskedMessage {
-				return next(ctx, rec)
-			}
-
-			nrec := slog.NewRecord(rec.Time, rec.Level, msg, rec.PC)
-			nrec.AddAttrs(nattrs...)
-
-			return next(ctx, nrec)
+			return next(ctx, rec)
 		}
 	}
 }
 
-func maskAttr(secrets []string, replacement string, attr slog.Attr) (res slog.Attr, masked bool) {
-	attr.Value = attr.Value.Resolve()
-
+func maskAttr(secrets []string, replacement string, attr slog.Attr) slog.Attr {
 	str, ok := stringValue(attr)
 	if !ok {
-		return attr, false
+		return attr
+	}
+
+	str, masked := mask(secrets, replacement, str)
+	if !masked {
+		return attr
 	}
 
-	str, masked = mask(secrets, replacement, str)
-	return slog.String(attr.Key, str), masked
+	return slog.String(attr.Key, str)
 }
 
 func mask(secrets []string, replacement, str string) (res string, masked bool) {
@@ -3,6 +3,7 @@ package slogm
 import (
 	"context"
 	"encoding/json"
+	"github.com/cappuccinotm/slogx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"log/slog"
@@ -305,3 +306,22 @@ func TestMaskSecrets(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestMaskSecrets_Groups(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	rec := slog.NewRecord(time.Now(), slog.LevelDebug, "test", pcs[0])
+	rec.AddAttrs(
+		slog.Int("int", 1),
+		slog.Group("request", slog.Group("headers", slog.String("Authorization", "Bearer secret"))),
+	)
+
+	h := MaskSecrets("***")(func(ctx context.Context, nrec slog.Record) error {
+		assert.Equal(t, []slog.Attr{
+			slog.Int("int", 1),
+			slog.Group("request", slog.Group("headers", slog.String("Authorization", "Bearer ***"))),
+		}, slogx.Attrs(nrec))
+		return nil
+	})
+	require.NoError(t, h(AddSecrets(context.Background(), "secret"), rec))
+}
@@ -11,11 +11,11 @@ import (
 
 var reTrace = regexp.MustCompile(`.*/slog/logger\.go.*\n`)
 
-// StacktraceOnError returns a middleware that adds stacktrace to record if level is error.
+// StacktraceOnError returns a middleware that adds stacktrace to record if level is error or above.
 func StacktraceOnError() slogx.Middleware {
 	return func(next slogx.HandleFunc) slogx.HandleFunc {
 		return func(ctx context.Context, rec slog.Record) error {
-			if rec.Level != slog.LevelError {
+			if rec.Level < slog.LevelError {
 				return next(ctx, rec)
 			}
 
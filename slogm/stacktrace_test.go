@@ -67,6 +67,27 @@ func TestStacktraceOnError(t *testing.T) {
 		assert.True(t, found)
 	})
 
+	t.Run("critical level", func(t *testing.T) {
+		mw := StacktraceOnError()
+
+		found := false
+		fn := mw(func(ctx context.Context, rec slog.Record) error {
+			rec.Attrs(func(attr slog.Attr) bool {
+				found = attr.Key == "stacktrace"
+				return !found
+			})
+			return nil
+		})
+
+		err := fn(context.Background(), slog.Record{
+			Level:   slogx.LevelCritical,
+			Message: "oh my! something critical occurred",
+		})
+		require.NoError(t, err)
+
+		assert.True(t, found)
+	})
+
 	t.Run("info level", func(t *testing.T) {
 		mw := StacktraceOnError()
 
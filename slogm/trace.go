@@ -0,0 +1,164 @@
+package slogm
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// SpanContext identifies the span, the record is logged in.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+// IsValid reports whether both trace and span IDs are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Traceparent returns the W3C traceparent header of the span context.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%x-%x-%02x", sc.TraceID, sc.SpanID, sc.Flags)
+}
+
+// ParseTraceparent parses the W3C traceparent header, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func ParseTraceparent(s string) (SpanContext, error) {
+	const size = 55 // version, trace ID, span ID and flags with dashes
+
+	if len(s) < size || (len(s) > size && s[size] != '-') {
+		return SpanContext{}, fmt.Errorf("invalid traceparent length %d", len(s))
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return SpanContext{}, errors.New("invalid traceparent delimiters")
+	}
+
+	var (
+		sc             SpanContext
+		version, flags [1]byte
+	)
+	for _, f := range []struct {
+		dst  []byte
+		src  string
+		name string
+	}{
+		{version[:], s[0:2], "version"},
+		{sc.TraceID[:], s[3:35], "trace ID"},
+		{sc.SpanID[:], s[36:52], "span ID"},
+		{flags[:], s[53:55], "flags"},
+	} {
+		if _, err := hex.Decode(f.dst, []byte(f.src)); err != nil {
+			return SpanContext{}, fmt.Errorf("invalid traceparent %s: %w", f.name, err)
+		}
+	}
+	sc.Flags = flags[0]
+
+	switch {
+	case version[0] == 0xff:
+		return SpanContext{}, errors.New("invalid traceparent version ff")
+	case version[0] == 0 && len(s) > size:
+		return SpanContext{}, errors.New("unexpected traceparent fields for version 00")
+	case !sc.IsValid():
+		return SpanContext{}, errors.New("traceparent with zero trace or span ID")
+	}
+
+	return sc, nil
+}
+
+type traceparentKey struct{}
+
+// ContextWithTraceparent returns a new context with the given W3C traceparent
+// header, e.g. the one received in the request. It is used by TraceContext,
+// if there is no span context found by the extractor.
+func ContextWithTraceparent(parent context.Context, traceparent string) context.Context {
+	return context.WithValue(parent, traceparentKey{}, traceparent)
+}
+
+// TraceparentFromContext returns the span context, parsed from the traceparent
+// in the context. It returns false, if there is no traceparent in the context
+// or if it is invalid.
+func TraceparentFromContext(ctx context.Context) (SpanContext, bool) {
+	s, ok := ctx.Value(traceparentKey{}).(string)
+	if !ok {
+		return SpanContext{}, false
+	}
+
+	sc, err := ParseTraceparent(s)
+	return sc, err == nil
+}
+
+type traceOptions struct {
+	traceIDKey, spanIDKey, flagsKey string
+	group                           string
+	extract                         func(context.Context) (SpanContext, bool)
+}
+
+// TraceOption is a functional option for TraceContext.
+type TraceOption func(*traceOptions)
+
+// TraceKeys sets the keys of the trace ID, span ID and trace flags.
+// Default is "trace_id", "span_id" and "trace_flags".
+func TraceKeys(traceID, spanID, flags string) TraceOption {
+	return func(o *traceOptions) { o.traceIDKey, o.spanIDKey, o.flagsKey = traceID, spanID, flags }
+}
+
+// TraceGroup makes TraceContext add the attributes in the group with
+// the given name, e.g. "trace": {"trace_id": ..., "span_id": ...}.
+func TraceGroup(name string) TraceOption { return func(o *traceOptions) { o.group = name } }
+
+// TraceExtractor sets the function to get the span context of the active
+// span from the context, e.g. the one of OpenTelemetry, provided by
+// the slogotel module. The traceparent from the context is used,
+// if the function doesn't find the span.
+func TraceExtractor(fn func(context.Context) (SpanContext, bool)) TraceOption {
+	return func(o *traceOptions) { o.extract = fn }
+}
+
+// TraceContext returns a middleware that adds the trace ID, span ID and
+// trace flags of the active span to the record. The span is looked up with
+// the extractor, set by TraceExtractor, and then in the traceparent, added
+// to the context with ContextWithTraceparent. Records without a span are
+// passed as is.
+func TraceContext(opts ...TraceOption) slogx.Middleware {
+	o := traceOptions{
+		traceIDKey: "trace_id",
+		spanIDKey:  "span_id",
+		flagsKey:   "trace_flags",
+		extract:    func(context.Context) (SpanContext, bool) { return SpanContext{}, false },
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(next slogx.HandleFunc) slogx.HandleFunc {
+		return func(ctx context.Context, rec slog.Record) error {
+			sc, ok := o.extract(ctx)
+			if !ok || !sc.IsValid() {
+				sc, ok = TraceparentFromContext(ctx)
+			}
+			if !ok {
+				return next(ctx, rec)
+			}
+
+			attrs := []slog.Attr{
+				slog.String(o.traceIDKey, hex.EncodeToString(sc.TraceID[:])),
+				slog.String(o.spanIDKey, hex.EncodeToString(sc.SpanID[:])),
+				slog.String(o.flagsKey, fmt.Sprintf("%02x", sc.Flags)),
+			}
+
+			if o.group != "" {
+				rec.AddAttrs(slog.Attr{Key: o.group, Value: slog.GroupValue(attrs...)})
+			} else {
+				rec.AddAttrs(attrs...)
+			}
+
+			return next(ctx, rec)
+		}
+	}
+}
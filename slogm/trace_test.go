@@ -0,0 +1,110 @@
+package slogm
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(testTraceparent)
+	require.NoError(t, err)
+	assert.Equal(t, SpanContext{
+		TraceID: [16]byte{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  [8]byte{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		Flags:   1,
+	}, sc)
+	assert.Equal(t, testTraceparent, sc.Traceparent())
+
+	// future versions may have more fields
+	_, err = ParseTraceparent("01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future")
+	assert.NoError(t, err)
+
+	for name, tc := range map[string]struct{ s, err string }{
+		"short":         {s: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", err: "invalid traceparent length"},
+		"delimiters":    {s: "00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01", err: "invalid traceparent delimiters"},
+		"trace id":      {s: "00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01", err: "invalid traceparent trace ID"},
+		"zero span id":  {s: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", err: "zero trace or span ID"},
+		"version ff":    {s: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", err: "invalid traceparent version ff"},
+		"extra for 00":  {s: testTraceparent + "-extra", err: "unexpected traceparent fields"},
+		"no delimiter":  {s: testTraceparent + "extra", err: "invalid traceparent length"},
+		"invalid flags": {s: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x", err: "invalid traceparent flags"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseTraceparent(tc.s)
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestTraceparentFromContext(t *testing.T) {
+	_, ok := TraceparentFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = TraceparentFromContext(ContextWithTraceparent(context.Background(), "invalid"))
+	assert.False(t, ok)
+
+	sc, ok := TraceparentFromContext(ContextWithTraceparent(context.Background(), testTraceparent))
+	require.True(t, ok)
+	assert.Equal(t, testTraceparent, sc.Traceparent())
+}
+
+func TestTraceContext(t *testing.T) {
+	handle := func(ctx context.Context, mw slogx.Middleware) map[string]any {
+		var attrs map[string]any
+		err := mw(func(_ context.Context, rec slog.Record) error {
+			attrs = map[string]any{}
+			rec.Attrs(func(a slog.Attr) bool {
+				attrs[a.Key] = a.Value.Resolve().Any()
+				return true
+			})
+			return nil
+		})(ctx, slog.Record{})
+		require.NoError(t, err)
+		return attrs
+	}
+
+	ctx := ContextWithTraceparent(context.Background(), testTraceparent)
+
+	t.Run("traceparent", func(t *testing.T) {
+		assert.Equal(t, map[string]any{
+			"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":     "00f067aa0ba902b7",
+			"trace_flags": "01",
+		}, handle(ctx, TraceContext()))
+	})
+
+	t.Run("no span", func(t *testing.T) {
+		assert.Empty(t, handle(context.Background(), TraceContext()))
+	})
+
+	t.Run("keys and group", func(t *testing.T) {
+		attrs := handle(ctx, TraceContext(TraceKeys("tid", "sid", "flags"), TraceGroup("trace")))
+		require.Contains(t, attrs, "trace")
+		assert.Equal(t, []slog.Attr{
+			slog.String("tid", "4bf92f3577b34da6a3ce929d0e0e4736"),
+			slog.String("sid", "00f067aa0ba902b7"),
+			slog.String("flags", "01"),
+		}, attrs["trace"])
+	})
+
+	t.Run("extractor", func(t *testing.T) {
+		extracted := SpanContext{TraceID: [16]byte{1}, SpanID: [8]byte{2}}
+		mw := TraceContext(TraceExtractor(func(context.Context) (SpanContext, bool) { return extracted, true }))
+		assert.Equal(t, map[string]any{
+			"trace_id":    "01000000000000000000000000000000",
+			"span_id":     "0200000000000000",
+			"trace_flags": "00",
+		}, handle(ctx, mw))
+
+		// falls back to the traceparent, if the extractor doesn't find the span
+		mw = TraceContext(TraceExtractor(func(context.Context) (SpanContext, bool) { return SpanContext{}, false }))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handle(ctx, mw)["trace_id"])
+	})
+}
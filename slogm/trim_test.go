@@ -3,6 +3,7 @@ package slogm
 import (
 	"context"
 	"encoding/json"
+	"github.com/cappuccinotm/slogx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"log/slog"
@@ -199,3 +200,25 @@ func TestTrimAttrs(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestTrimAttrs_Groups(t *testing.T) {
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+	tm := time.Now()
+	rec := slog.NewRecord(tm, slog.LevelDebug, "test", pcs[0])
+	rec.AddAttrs(
+		slog.Int("int", 1),
+		slog.Group("g", slog.String("short", "value"), slog.String("long", "value_very_long")),
+	)
+
+	h := TrimAttrs(5)(func(ctx context.Context, nrec slog.Record) error {
+		assert.Equal(t, tm, nrec.Time)
+		assert.Equal(t, pcs[0], nrec.PC)
+		assert.Equal(t, []slog.Attr{
+			slog.Int("int", 1),
+			slog.Group("g", slog.String("short", "value"), slog.String("long", "value...")),
+		}, slogx.Attrs(nrec))
+		return nil
+	})
+	require.NoError(t, h(context.Background(), rec))
+}
@@ -0,0 +1,209 @@
+package slogotel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type eventOptions struct {
+	lvl          slog.Leveler
+	levelKey     string
+	errorStatus  bool
+	recordErrors bool
+}
+
+// EventOption is a functional option for SpanEvents.
+type EventOption func(*eventOptions)
+
+// EventLevel sets the minimum level of the records to add as span events,
+// e.g. slog.LevelWarn. By default, all the records are added.
+func EventLevel(lvl slog.Leveler) EventOption { return func(o *eventOptions) { o.lvl = lvl } }
+
+// EventLevelKey sets the key of the event attribute with the name of
+// the record's level. Default is "level", empty key omits the level.
+func EventLevelKey(key string) EventOption { return func(o *eventOptions) { o.levelKey = key } }
+
+// SetErrorStatus makes SpanEvents set the status of the span to codes.Error
+// with the message of the record, when the record is ERROR or above.
+func SetErrorStatus(o *eventOptions) { o.errorStatus = true }
+
+// RecordErrors makes SpanEvents record the error, logged with slogx.Error,
+// as the exception event of the span, when the record is ERROR or above.
+func RecordErrors(o *eventOptions) { o.recordErrors = true }
+
+// SpanEvents returns an interceptor, that adds the records as events to the
+// active OpenTelemetry span from the context. The name of the event is the
+// message of the record, attributes, including the ones of the logger,
+// are converted to the OpenTelemetry ones, groups are flattened into
+// dot-separated keys, e.g. "request.method". Records are passed down
+// the chain as is.
+// Usage: slogx.NewChain(h).Use(slogotel.SpanEvents(slogotel.RecordErrors))
+func SpanEvents(opts ...EventOption) slogx.Interceptor {
+	o := &eventOptions{lvl: slog.Level(math.MinInt), levelKey: slog.LevelKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return eventsInterceptor{opts: o}
+}
+
+type eventsInterceptor struct {
+	opts   *eventOptions
+	attrs  []attribute.KeyValue // attributes of the logger, already converted
+	prefix string               // dot-separated groups of the logger
+}
+
+// Wrap adds the record as the event to the active span and passes it down the chain.
+func (ei eventsInterceptor) Wrap(next slogx.HandleFunc) slogx.HandleFunc {
+	return func(ctx context.Context, rec slog.Record) error {
+		span := trace.SpanFromContext(ctx)
+		if !span.IsRecording() || rec.Level < ei.opts.lvl.Level() {
+			return next(ctx, rec)
+		}
+
+		attrs := make([]attribute.KeyValue, 0, len(ei.attrs)+rec.NumAttrs()+1)
+		if ei.opts.levelKey != "" {
+			attrs = append(attrs, attribute.String(ei.opts.levelKey, slogx.LevelName(rec.Level)))
+		}
+		attrs = append(attrs, ei.attrs...)
+		rec.Attrs(func(a slog.Attr) bool {
+			attrs = appendAttr(attrs, ei.prefix, a)
+			return true
+		})
+
+		var tsOpts []trace.EventOption
+		if !rec.Time.IsZero() {
+			tsOpts = append(tsOpts, trace.WithTimestamp(rec.Time))
+		}
+		span.AddEvent(rec.Message, append(tsOpts, trace.WithAttributes(attrs...))...)
+
+		if rec.Level < slog.LevelError {
+			return next(ctx, rec)
+		}
+
+		if ei.opts.errorStatus {
+			span.SetStatus(codes.Error, rec.Message)
+		}
+		if ei.opts.recordErrors {
+			if err := recordError(rec); err != nil {
+				span.RecordError(err, tsOpts...)
+			}
+		}
+
+		return next(ctx, rec)
+	}
+}
+
+// WithAttrs converts the attributes for the events and passes them down the chain.
+func (ei eventsInterceptor) WithAttrs(attrs []slog.Attr) (slogx.Interceptor, []slog.Attr) {
+	converted := make([]attribute.KeyValue, len(ei.attrs), len(ei.attrs)+len(attrs))
+	copy(converted, ei.attrs)
+	for _, a := range attrs {
+		converted = appendAttr(converted, ei.prefix, a)
+	}
+	return eventsInterceptor{opts: ei.opts, attrs: converted, prefix: ei.prefix}, attrs
+}
+
+// WithGroup prefixes the keys of the next attributes with the group and
+// passes it down the chain.
+func (ei eventsInterceptor) WithGroup(name string) (slogx.Interceptor, string) {
+	return eventsInterceptor{opts: ei.opts, attrs: ei.attrs, prefix: ei.prefix + name + "."}, name
+}
+
+// recordError returns the error of the record, logged with slogx.Error,
+// if any.
+func recordError(rec slog.Record) (err error) {
+	var find func(a slog.Attr) bool
+	find = func(a slog.Attr) bool {
+		switch {
+		case a.Key == "" && a.Value.Kind() == slog.KindGroup: // inlined attributes of slogx.Error
+			for _, ga := range a.Value.Group() {
+				if !find(ga) {
+					return false
+				}
+			}
+		case a.Key == slogx.ErrorKey && (a.Value.Kind() == slog.KindAny || a.Value.Kind() == slog.KindLogValuer):
+			if e, ok := a.Value.Any().(error); ok && e != nil {
+				err = e
+				return false
+			}
+		}
+		return true
+	}
+	rec.Attrs(find)
+
+	// errors in the structured format are wrapped to be rendered,
+	// the exception must describe the original one
+	return slogx.UnwrapStructured(err)
+}
+
+// appendAttr appends the attribute, converted to the OpenTelemetry ones,
+// flattening the groups.
+func appendAttr(attrs []attribute.KeyValue, prefix string, a slog.Attr) []attribute.KeyValue {
+	val := a.Value.Resolve()
+
+	if val.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range val.Group() {
+			attrs = appendAttr(attrs, prefix, ga)
+		}
+		return attrs
+	}
+
+	if a.Key == "" && val.Kind() == slog.KindAny && val.Any() == nil {
+		return attrs // empty attribute
+	}
+
+	return append(attrs, convert(prefix+a.Key, val))
+}
+
+// convert converts the resolved value to the OpenTelemetry attribute.
+func convert(key string, val slog.Value) attribute.KeyValue {
+	switch val.Kind() {
+	case slog.KindString:
+		return attribute.String(key, val.String())
+	case slog.KindInt64:
+		return attribute.Int64(key, val.Int64())
+	case slog.KindUint64:
+		if u := val.Uint64(); u <= math.MaxInt64 {
+			return attribute.Int64(key, int64(u))
+		}
+		return attribute.String(key, val.String())
+	case slog.KindFloat64:
+		return attribute.Float64(key, val.Float64())
+	case slog.KindBool:
+		return attribute.Bool(key, val.Bool())
+	case slog.KindDuration:
+		return attribute.String(key, val.Duration().String())
+	case slog.KindTime:
+		return attribute.String(key, val.Time().Format(time.RFC3339Nano))
+	}
+
+	switch v := val.Any().(type) {
+	case error:
+		return attribute.String(key, v.Error())
+	case []string:
+		return attribute.StringSlice(key, v)
+	case []int:
+		return attribute.IntSlice(key, v)
+	case []int64:
+		return attribute.Int64Slice(key, v)
+	case []float64:
+		return attribute.Float64Slice(key, v)
+	case []bool:
+		return attribute.BoolSlice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
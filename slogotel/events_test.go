@@ -0,0 +1,167 @@
+package slogotel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// record starts the span, runs fn with its context and returns
+// the ended span.
+func record(t *testing.T, fn func(ctx context.Context)) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	defer tp.Shutdown(context.Background())
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "span")
+	fn(ctx)
+	span.End()
+
+	spans := sr.Ended()
+	require.Len(t, spans, 1)
+	return spans[0]
+}
+
+func newLogger(opts ...EventOption) *slog.Logger {
+	h := slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})
+	return slog.New(slogx.NewChain(h).Use(SpanEvents(opts...)))
+}
+
+func TestSpanEvents(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	span := record(t, func(ctx context.Context) {
+		lg := newLogger().With("service", "api").WithGroup("request").With(slog.String("method", "GET"))
+
+		rec := slog.NewRecord(ts, slog.LevelWarn, "slow request", 0)
+		rec.AddAttrs(
+			slog.Group("headers", slog.String("Accept", "*/*")),
+			slog.Int("status", 200),
+			slog.Uint64("size", 1<<63),
+			slog.Float64("ratio", 0.5),
+			slog.Bool("cached", false),
+			slog.Duration("took", time.Second),
+			slog.Time("at", ts),
+			slog.Any("ids", []int{1, 2}),
+			slog.Any("map", map[string]int{"a": 1}),
+			slog.Group("", slog.String("inline", "yes")),
+			slog.Attr{},
+		)
+		require.NoError(t, lg.Handler().Handle(ctx, rec))
+	})
+
+	require.Len(t, span.Events(), 1)
+	ev := span.Events()[0]
+	assert.Equal(t, "slow request", ev.Name)
+	assert.Equal(t, ts, ev.Time)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("level", "WARN"),
+		attribute.String("service", "api"),
+		attribute.String("request.method", "GET"),
+		attribute.String("request.headers.Accept", "*/*"),
+		attribute.Int64("request.status", 200),
+		attribute.String("request.size", "9223372036854775808"),
+		attribute.Float64("request.ratio", 0.5),
+		attribute.Bool("request.cached", false),
+		attribute.String("request.took", "1s"),
+		attribute.String("request.at", "2026-01-02T15:04:05Z"),
+		attribute.IntSlice("request.ids", []int{1, 2}),
+		attribute.String("request.map", "map[a:1]"),
+		attribute.String("request.inline", "yes"),
+	}, ev.Attributes)
+	assert.Equal(t, codes.Unset, span.Status().Code)
+}
+
+func TestSpanEvents_Errors(t *testing.T) {
+	errTest := errors.New("connection refused")
+
+	t.Run("status and exception", func(t *testing.T) {
+		span := record(t, func(ctx context.Context) {
+			lg := newLogger(SetErrorStatus, RecordErrors)
+			lg.ErrorContext(ctx, "failed to connect", slogx.Error(slogx.WrapErr(errTest, slog.String("host", "db"))))
+		})
+
+		require.Len(t, span.Events(), 2)
+		assert.Equal(t, "failed to connect", span.Events()[0].Name)
+		assert.Equal(t, []attribute.KeyValue{
+			attribute.String("level", "ERROR"),
+			attribute.String("error", "connection refused"),
+			attribute.String("host", "db"),
+		}, span.Events()[0].Attributes)
+
+		assert.Equal(t, "exception", span.Events()[1].Name)
+		assert.Contains(t, span.Events()[1].Attributes, attribute.String("exception.message", "connection refused"))
+		assert.Equal(t, sdktrace.Status{Code: codes.Error, Description: "failed to connect"}, span.Status())
+	})
+
+	t.Run("structured error", func(t *testing.T) {
+		defer func(f slogx.ErrFormat) { slogx.ErrAttrFormat = f }(slogx.ErrAttrFormat)
+		slogx.ErrAttrFormat = slogx.ErrFormatStructured
+
+		span := record(t, func(ctx context.Context) {
+			lg := newLogger(RecordErrors)
+			lg.ErrorContext(ctx, "failed", slogx.Error(errTest))
+		})
+
+		require.Len(t, span.Events(), 2)
+		assert.Contains(t, span.Events()[1].Attributes, attribute.String("exception.type", "*errors.errorString"))
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+
+	t.Run("other log valuer errors are kept", func(t *testing.T) {
+		span := record(t, func(ctx context.Context) {
+			lg := newLogger(RecordErrors)
+			lg.ErrorContext(ctx, "failed", slogx.Error(valuerError{errTest}))
+		})
+
+		require.Len(t, span.Events(), 2)
+		assert.Contains(t, span.Events()[1].Attributes, attribute.String("exception.type", "github.com/cappuccinotm/slogx/slogotel.valuerError"))
+	})
+
+	t.Run("not an error level", func(t *testing.T) {
+		span := record(t, func(ctx context.Context) {
+			lg := newLogger(SetErrorStatus, RecordErrors)
+			lg.WarnContext(ctx, "retrying", slogx.Error(errTest))
+		})
+
+		require.Len(t, span.Events(), 1)
+		assert.Equal(t, codes.Unset, span.Status().Code)
+	})
+}
+
+func TestSpanEvents_Options(t *testing.T) {
+	span := record(t, func(ctx context.Context) {
+		lg := newLogger(EventLevel(slog.LevelWarn), EventLevelKey(""))
+		lg.InfoContext(ctx, "skipped")
+		lg.WarnContext(ctx, "added", "k", "v")
+	})
+
+	require.Len(t, span.Events(), 1)
+	assert.Equal(t, "added", span.Events()[0].Name)
+	assert.Equal(t, []attribute.KeyValue{attribute.String("k", "v")}, span.Events()[0].Attributes)
+
+	// no span in the context
+	var passed bool
+	h := SpanEvents().Wrap(func(context.Context, slog.Record) error { passed = true; return nil })
+	require.NoError(t, h(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)))
+	assert.True(t, passed)
+}
+
+// valuerError is an error, that is also a slog.LogValuer and wraps the cause.
+type valuerError struct{ error }
+
+func (e valuerError) LogValue() slog.Value { return slog.StringValue(e.Error()) }
+func (e valuerError) Unwrap() error        { return e.error }
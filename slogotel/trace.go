@@ -0,0 +1,34 @@
+// Package slogotel integrates slogx with OpenTelemetry. It is a separate
+// module, so that the core of slogx stays free of dependencies.
+package slogotel
+
+import (
+	"context"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/slogm"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SpanContext returns the span context of the active OpenTelemetry span
+// in the context. It is the extractor for slogm.TraceExtractor.
+func SpanContext(ctx context.Context) (slogm.SpanContext, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return slogm.SpanContext{}, false
+	}
+
+	return slogm.SpanContext{
+		TraceID: sc.TraceID(),
+		SpanID:  sc.SpanID(),
+		Flags:   byte(sc.TraceFlags()),
+	}, true
+}
+
+// TraceContext returns a middleware that adds the trace ID, span ID and
+// trace flags of the active OpenTelemetry span to the record, falling back
+// to the traceparent, added with slogm.ContextWithTraceparent.
+// It accepts the options of slogm.TraceContext.
+func TraceContext(opts ...slogm.TraceOption) slogx.Middleware {
+	return slogm.TraceContext(append([]slogm.TraceOption{slogm.TraceExtractor(SpanContext)}, opts...)...)
+}
@@ -0,0 +1,63 @@
+package slogotel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/cappuccinotm/slogx/slogm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestTraceContext(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "span")
+	defer span.End()
+	sc := span.SpanContext()
+
+	buf := &bytes.Buffer{}
+	lg := slog.New(slogx.NewChain(slog.NewJSONHandler(buf, nil), TraceContext(slogm.TraceGroup("trace"))))
+
+	read := func() map[string]any {
+		t.Helper()
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+		buf.Reset()
+		return entry
+	}
+
+	t.Run("otel span", func(t *testing.T) {
+		lg.InfoContext(ctx, "message")
+		assert.Equal(t, map[string]any{
+			"trace_id":    sc.TraceID().String(),
+			"span_id":     sc.SpanID().String(),
+			"trace_flags": "01",
+		}, read()["trace"])
+	})
+
+	t.Run("otel span over traceparent", func(t *testing.T) {
+		lg.InfoContext(slogm.ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"), "message")
+		assert.Equal(t, sc.TraceID().String(), read()["trace"].(map[string]any)["trace_id"])
+	})
+
+	t.Run("traceparent", func(t *testing.T) {
+		lg.InfoContext(slogm.ContextWithTraceparent(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"), "message")
+		assert.Equal(t, map[string]any{
+			"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":     "00f067aa0ba902b7",
+			"trace_flags": "00",
+		}, read()["trace"])
+	})
+
+	t.Run("no span", func(t *testing.T) {
+		lg.InfoContext(context.Background(), "message")
+		assert.NotContains(t, read(), "trace")
+	})
+}
@@ -0,0 +1,24 @@
+package slogt
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/cappuccinotm/slogx/internal/levels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CustomLevels(t *testing.T) {
+	tm := &testTMock{t: t}
+	l := slog.New(Handler(tm))
+	l.Log(context.Background(), levels.Notice, "notice")
+	l.Log(context.Background(), levels.Trace, "trace")
+	l.Log(context.Background(), slog.LevelDebug-1, "verbose")
+
+	require.Len(t, tm.rows, 3)
+	assert.Contains(t, tm.rows[0], " l=NOTICE ")
+	assert.Contains(t, tm.rows[1], " l=TRACE ")
+	assert.Contains(t, tm.rows[2], " l=TRACE+3 ")
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"strings"
+
+	"github.com/cappuccinotm/slogx/internal/levels"
 )
 
 type testingOpts struct {
@@ -31,13 +33,16 @@ func Handler(t testingT, topts ...TestingOpt) slog.Handler {
 
 	handlerOpts := &slog.HandlerOptions{
 		AddSource: true,
-		Level:     slog.LevelDebug,
+		Level:     levels.Trace,
 		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
 			switch {
 			case a.Key == slog.TimeKey: // shorten full time to "15:04:05.000"
 				tt := a.Value.Time()
 				return slog.String("t", tt.Format("15:04:05.000"))
 			case a.Key == slog.LevelKey: // shorten "level":"debug" to "l":"debug"
+				if lvl, ok := a.Value.Any().(slog.Level); ok {
+					return slog.String("l", levels.Name(lvl))
+				}
 				return slog.String("l", a.Value.String())
 			case a.Key == slog.SourceKey: // shorten "source":"full/path/to/file.go:123" to "s":"file.go:123"
 				src := a.Value.Any().(*slog.Source)
@@ -29,6 +29,44 @@ type HandleFunc func(context.Context, slog.Record) error
 // Middleware is a middleware for logging handler.
 type Middleware func(HandleFunc) HandleFunc
 
+// Wrap implements Interceptor.
+func (m Middleware) Wrap(next HandleFunc) HandleFunc { return m(next) }
+
+// EnabledFunc is a function that reports whether the level is enabled.
+type EnabledFunc func(context.Context, slog.Level) bool
+
+// Interceptor is an extended middleware contract. Besides wrapping the
+// HandleFunc, it may implement any of EnabledInterceptor, AttrsInterceptor
+// and GroupInterceptor to participate in the respective calls of the Chain.
+// Middleware implements Interceptor.
+type Interceptor interface {
+	Wrap(next HandleFunc) HandleFunc
+}
+
+// EnabledInterceptor is an Interceptor, that participates in the Enabled
+// calls, e.g. to filter records by level or context before they are built.
+type EnabledInterceptor interface {
+	Interceptor
+	WrapEnabled(next EnabledFunc) EnabledFunc
+}
+
+// AttrsInterceptor is an Interceptor, that participates in the WithAttrs calls.
+// It returns the Interceptor to use in the derived chain and the attributes
+// to pass to the rest of the chain, e.g. it may keep the attributes to itself,
+// returning none of them, and add them to the records on its own.
+type AttrsInterceptor interface {
+	Interceptor
+	WithAttrs(attrs []slog.Attr) (Interceptor, []slog.Attr)
+}
+
+// GroupInterceptor is an Interceptor, that participates in the WithGroup calls.
+// It returns the Interceptor to use in the derived chain and the group name
+// to pass to the rest of the chain, empty name means not to pass the group.
+type GroupInterceptor interface {
+	Interceptor
+	WithGroup(name string) (Interceptor, string)
+}
+
 // ErrAttrStrategy specifies how to log errors.
 // "AsIs" logs nils, when the error is nil, if you want to not
 // log nils, use "None".
@@ -41,11 +79,25 @@ type Middleware func(HandleFunc) HandleFunc
 var ErrAttrStrategy = LogAttrAsIs
 
 // Error returns an attribute with error key.
+// The error is rendered according to ErrAttrFormat.
+// If the error carries attributes, attached with WrapErr, Error returns
+// a group with an empty key, that handlers inline, i.e. the attributes
+// are logged next to the error.
 func Error(err error) slog.Attr {
 	if err == nil && ErrAttrStrategy == LogAttrNone {
 		return slog.Attr{}
 	}
-	return slog.Any(ErrorKey, err)
+
+	attr := slog.Any(ErrorKey, err)
+	if err != nil && ErrAttrFormat == ErrFormatStructured {
+		attr = slog.Any(ErrorKey, structuredError{err})
+	}
+
+	if attrs := ErrorAttrs(err); len(attrs) > 0 {
+		return slog.Attr{Key: "", Value: slog.GroupValue(append([]slog.Attr{attr}, attrs...)...)}
+	}
+
+	return attr
 }
 
 // Attrs returns attributes from the given record.
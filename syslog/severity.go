@@ -0,0 +1,80 @@
+package syslog
+
+import (
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+)
+
+// Severity is the syslog severity of the message, as defined in RFC 5424.
+type Severity uint8
+
+// Severities, as defined in RFC 5424.
+const (
+	SeverityEmergency Severity = iota
+	SeverityAlert
+	SeverityCritical
+	SeverityError
+	SeverityWarning
+	SeverityNotice
+	SeverityInformational
+	SeverityDebug
+)
+
+// SeverityOf returns the syslog severity of the slog level:
+//   - slogx.LevelFatal and above are SeverityAlert;
+//   - slogx.LevelCritical and above are SeverityCritical;
+//   - slog.LevelError and above are SeverityError;
+//   - slog.LevelWarn and above are SeverityWarning;
+//   - slogx.LevelNotice and above are SeverityNotice;
+//   - slog.LevelInfo and above are SeverityInformational;
+//   - the rest are SeverityDebug.
+func SeverityOf(lvl slog.Level) Severity {
+	switch {
+	case lvl >= slogx.LevelFatal:
+		return SeverityAlert
+	case lvl >= slogx.LevelCritical:
+		return SeverityCritical
+	case lvl >= slog.LevelError:
+		return SeverityError
+	case lvl >= slog.LevelWarn:
+		return SeverityWarning
+	case lvl >= slogx.LevelNotice:
+		return SeverityNotice
+	case lvl >= slog.LevelInfo:
+		return SeverityInformational
+	default:
+		return SeverityDebug
+	}
+}
+
+// Facility is the syslog facility of the message, as defined in RFC 5424.
+type Facility uint8
+
+// Facilities, as defined in RFC 5424.
+const (
+	FacilityKern Facility = iota
+	FacilityUser
+	FacilityMail
+	FacilityDaemon
+	FacilityAuth
+	FacilitySyslog
+	FacilityLPR
+	FacilityNews
+	FacilityUUCP
+	FacilityCron
+	FacilityAuthPriv
+	FacilityFTP
+	FacilityNTP
+	FacilityAudit
+	FacilityAlert
+	FacilityClock
+	FacilityLocal0
+	FacilityLocal1
+	FacilityLocal2
+	FacilityLocal3
+	FacilityLocal4
+	FacilityLocal5
+	FacilityLocal6
+	FacilityLocal7
+)
@@ -0,0 +1,326 @@
+// Package syslog provides a slog.Handler, that sends records to the syslog
+// server, e.g. local rsyslog, in the RFC 5424 format, e.g.:
+//
+//	<14>1 2006-01-02T15:04:05.000000Z host app 42 - [slog user="john"][request method="GET"] message
+//
+// Attributes of the record are written as structured data: top-level
+// attributes are the parameters of the SD-ELEMENT with the default ID
+// ("slog"), each top-level group becomes the SD-ELEMENT with the group
+// name as its ID, nested groups are flattened into the parameter names,
+// joined with dots.
+package syslog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cappuccinotm/slogx/internal/grouped"
+	"github.com/cappuccinotm/slogx/internal/netconn"
+)
+
+const (
+	nilValue    = "-"
+	maxNameSize = 32 // maximal length of SD-IDs and PARAM-NAMEs
+
+	// timestampFormat is the RFC 3339 format with microseconds,
+	// allowed by RFC 5424.
+	timestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+)
+
+type options struct {
+	lvl          slog.Leveler
+	facility     Facility
+	hostname     string
+	appName      string
+	msgID        string
+	defaultSDID  string
+	enterpriseID string
+	timeout      time.Duration
+}
+
+// Option is a functional option for New.
+type Option func(*options)
+
+// WithLevel sets the minimum level of the records to send.
+// Default is slog.LevelInfo.
+func WithLevel(lvl slog.Leveler) Option { return func(o *options) { o.lvl = lvl } }
+
+// WithFacility sets the facility of the messages. Default is FacilityUser.
+func WithFacility(f Facility) Option { return func(o *options) { o.facility = f } }
+
+// Hostname sets the HOSTNAME field of the messages. Default is os.Hostname.
+func Hostname(name string) Option { return func(o *options) { o.hostname = name } }
+
+// AppName sets the APP-NAME field of the messages.
+// Default is the name of the executable.
+func AppName(name string) Option { return func(o *options) { o.appName = name } }
+
+// MsgID sets the MSGID field of the messages. By default, it is empty.
+func MsgID(id string) Option { return func(o *options) { o.msgID = id } }
+
+// DefaultSDID sets the ID of the SD-ELEMENT for the top-level attributes.
+// Default is "slog".
+func DefaultSDID(id string) Option { return func(o *options) { o.defaultSDID = id } }
+
+// EnterpriseID sets the private enterprise number, that is appended to all
+// SD-IDs after "@", as RFC 5424 reserves the names without it. By default,
+// SD-IDs are written as is, which is accepted by rsyslog and syslog-ng.
+func EnterpriseID(id string) Option { return func(o *options) { o.enterpriseID = id } }
+
+// Timeout sets the timeout of dialing and writing to the server.
+// Default is 5 seconds.
+func Timeout(d time.Duration) Option { return func(o *options) { o.timeout = d } }
+
+// Handler sends records to the syslog server.
+type Handler struct {
+	conn  *netconn.Conn
+	opts  *options
+	pid   string
+	attrs grouped.Attrs
+}
+
+var bufPool = sync.Pool{New: func() any { b := make([]byte, 0, 1024); return &b }}
+
+// New connects to the syslog server and returns the Handler, that sends
+// records to it. The network is one of "unixgram", "udp" (each message
+// is sent as a separate datagram), "unix" or "tcp" (messages are framed
+// with octet counting, as defined in RFC 6587). The connection is
+// reestablished on errors. If the network is "unixgram" and the address
+// is empty, "/dev/log" is used.
+func New(network, addr string, opts ...Option) (*Handler, error) {
+	o := &options{
+		lvl:         slog.LevelInfo,
+		facility:    FacilityUser,
+		appName:     filepath.Base(os.Args[0]),
+		defaultSDID: "slog",
+		timeout:     5 * time.Second,
+	}
+	o.hostname, _ = os.Hostname()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if network == "unixgram" && addr == "" {
+		addr = "/dev/log"
+	}
+
+	var frame netconn.FrameFunc
+	switch network {
+	case "tcp", "tcp4", "tcp6", "unix":
+		frame = appendFrame
+	case "udp", "udp4", "udp6", "unixgram":
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+
+	c, err := netconn.Dial(network, addr, o.timeout, frame)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Handler{conn: c, opts: o, pid: strconv.Itoa(os.Getpid())}, nil
+}
+
+// Enabled reports whether the level is at or above the minimum one.
+func (h *Handler) Enabled(_ context.Context, lvl slog.Level) bool {
+	return lvl >= h.opts.lvl.Level()
+}
+
+// Handle sends the record to the server.
+func (h *Handler) Handle(_ context.Context, rec slog.Record) error {
+	bufp := bufPool.Get().(*[]byte)
+	defer bufPool.Put(bufp)
+
+	*bufp = h.appendMessage((*bufp)[:0], rec)
+	return h.conn.Write(*bufp)
+}
+
+// WithAttrs returns a new Handler with the given attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	hh := *h
+	hh.attrs = h.attrs.WithAttrs(attrs)
+	return &hh
+}
+
+// WithGroup returns a new Handler with the given group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	hh := *h
+	hh.attrs = h.attrs.WithGroup(name)
+	return &hh
+}
+
+// Close closes the connection to the server.
+func (h *Handler) Close() error { return h.conn.Close() }
+
+// appendFrame appends the message, prefixed with its length,
+// as defined by the octet-counting framing of RFC 6587.
+func appendFrame(dst, msg []byte) []byte {
+	dst = strconv.AppendInt(dst, int64(len(msg)), 10)
+	dst = append(dst, ' ')
+	return append(dst, msg...)
+}
+
+// appendMessage appends the RFC 5424 message:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+func (h *Handler) appendMessage(b []byte, rec slog.Record) []byte {
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(h.opts.facility)*8+int64(SeverityOf(rec.Level)), 10)
+	b = append(b, ">1 "...)
+
+	if rec.Time.IsZero() {
+		b = append(b, nilValue...)
+	} else {
+		b = rec.Time.AppendFormat(b, timestampFormat)
+	}
+
+	for _, field := range []struct {
+		val  string
+		size int
+	}{{h.opts.hostname, 255}, {h.opts.appName, 48}, {h.pid, 128}, {h.opts.msgID, 32}} {
+		b = append(b, ' ')
+		b = appendHeaderField(b, field.val, field.size)
+	}
+
+	b = append(b, ' ')
+	b = h.appendStructuredData(b, rec)
+
+	if rec.Message != "" {
+		b = append(b, ' ')
+		b = append(b, rec.Message...)
+	}
+
+	return b
+}
+
+// sdElement is the SD-ELEMENT under construction.
+type sdElement struct {
+	id     string
+	params []byte // already escaped, each one prefixed with a space
+}
+
+func (h *Handler) appendStructuredData(b []byte, rec slog.Record) []byte {
+	var elems []sdElement
+	h.attrs.Walk(rec, func(groups []string, a slog.Attr) {
+		elems = h.addAttr(elems, groups, a)
+	})
+
+	if len(elems) == 0 {
+		return append(b, nilValue...)
+	}
+
+	for _, e := range elems {
+		b = append(b, '[')
+		b = appendName(b, e.id)
+		if h.opts.enterpriseID != "" {
+			b = append(b, '@')
+			b = append(b, h.opts.enterpriseID...)
+		}
+		b = append(b, e.params...)
+		b = append(b, ']')
+	}
+
+	return b
+}
+
+// addAttr adds the resolved attribute with the given path of groups
+// to the elements, the first group in the path is the ID of the element.
+func (h *Handler) addAttr(elems []sdElement, groups []string, a slog.Attr) []sdElement {
+	id := h.opts.defaultSDID
+	if len(groups) > 0 {
+		id, groups = groups[0], groups[1:]
+	}
+
+	i := slices.IndexFunc(elems, func(e sdElement) bool { return e.id == id })
+	if i < 0 {
+		elems = append(elems, sdElement{id: id})
+		i = len(elems) - 1
+	}
+
+	p := append(elems[i].params, ' ')
+	p = appendName(p, strings.Join(append(slices.Clip(groups), a.Key), "."))
+	p = append(p, '=', '"')
+	p = appendParamValue(p, valueString(a.Value))
+	elems[i].params = append(p, '"')
+
+	return elems
+}
+
+func valueString(v slog.Value) string {
+	if v.Kind() == slog.KindTime {
+		return v.Time().Format(time.RFC3339Nano)
+	}
+	return v.String()
+}
+
+// appendHeaderField appends the header field, which consists of the
+// printable US-ASCII characters only, truncated to the size,
+// or the nil value, if the field is empty.
+func appendHeaderField(b []byte, s string, size int) []byte {
+	if s == "" {
+		return append(b, nilValue...)
+	}
+
+	for i := 0; i < len(s) && i < size; i++ {
+		c := s[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+
+	return b
+}
+
+// appendName appends the SD-ID or PARAM-NAME, replacing the characters,
+// that are not allowed in them, with underscores.
+func appendName(b []byte, s string) []byte {
+	if s == "" {
+		return append(b, '_')
+	}
+
+	for i := 0; i < len(s) && i < maxNameSize; i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' || c == '@' {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+
+	return b
+}
+
+// appendParamValue appends the PARAM-VALUE, escaping '"', '\' and ']'
+// and replacing the invalid UTF-8 sequences.
+func appendParamValue(b []byte, s string) []byte {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b = utf8.AppendRune(b, utf8.RuneError)
+		case r == '"' || r == '\\' || r == ']':
+			b = append(b, '\\', byte(r))
+		default:
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+	return b
+}
@@ -0,0 +1,233 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTime = time.Date(2026, 1, 2, 15, 4, 5, 123456789, time.UTC)
+
+func testRecord(lvl slog.Level, msg string, attrs ...slog.Attr) slog.Record {
+	rec := slog.NewRecord(testTime, lvl, msg, 0)
+	rec.AddAttrs(attrs...)
+	return rec
+}
+
+func listenUDP(t *testing.T) (addr string, read func() string) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pc.Close() })
+
+	return pc.LocalAddr().String(), func() string {
+		t.Helper()
+		buf := make([]byte, 64*1024)
+		require.NoError(t, pc.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+}
+
+func TestSeverityOf(t *testing.T) {
+	for lvl, sev := range map[slog.Level]Severity{
+		slogx.LevelTrace:    SeverityDebug,
+		slog.LevelDebug:     SeverityDebug,
+		slog.LevelInfo:      SeverityInformational,
+		slogx.LevelNotice:   SeverityNotice,
+		slog.LevelWarn:      SeverityWarning,
+		slog.LevelError:     SeverityError,
+		slogx.LevelCritical: SeverityCritical,
+		slogx.LevelFatal:    SeverityAlert,
+	} {
+		assert.Equal(t, sev, SeverityOf(lvl), slogx.LevelName(lvl))
+	}
+}
+
+func TestHandler_Format(t *testing.T) {
+	addr, read := listenUDP(t)
+
+	h, err := New("udp", addr, Hostname("host"), AppName("my app"), WithLevel(slog.LevelDebug))
+	require.NoError(t, err)
+	defer h.Close()
+	pid := strconv.Itoa(os.Getpid())
+
+	t.Run("no attributes", func(t *testing.T) {
+		require.NoError(t, h.Handle(context.Background(), testRecord(slog.LevelInfo, "message")))
+		assert.Equal(t, "<14>1 2026-01-02T15:04:05.123456Z host my_app "+pid+" - - message", read())
+	})
+
+	t.Run("structured data", func(t *testing.T) {
+		hh := h.WithAttrs([]slog.Attr{slog.String("service", "api")}).
+			WithGroup("request").
+			WithAttrs([]slog.Attr{slog.String("method", "GET")})
+
+		rec := testRecord(slog.LevelError, "failed",
+			slog.Group("headers", slog.String("Accept", "*/*")),
+			slog.String("note", `say "hi" \ [x]`),
+			slog.Group("", slog.Int("inline", 1)),
+		)
+		require.NoError(t, hh.Handle(context.Background(), rec))
+
+		assert.Equal(t, "<11>1 2026-01-02T15:04:05.123456Z host my_app "+pid+` - `+
+			`[slog service="api"]`+
+			`[request method="GET" headers.Accept="*/*" note="say \"hi\" \\ [x\]" inline="1"] failed`, read())
+	})
+
+	t.Run("names and values", func(t *testing.T) {
+		hh := h.WithGroup("user@example.com id")
+		rec := testRecord(slog.LevelDebug, "",
+			slog.String("a=b", "\xff"),
+			slog.Time("at", testTime),
+			slog.Group("empty"),
+		)
+		require.NoError(t, hh.Handle(context.Background(), rec))
+
+		assert.Equal(t, "<15>1 2026-01-02T15:04:05.123456Z host my_app "+pid+` - `+
+			`[user_example.com_id a_b="`+"\uFFFD"+`" at="2026-01-02T15:04:05.123456789Z"]`, read())
+	})
+}
+
+func TestHandler_UncomparableValues(t *testing.T) {
+	addr, read := listenUDP(t)
+
+	h, err := New("udp", addr, Hostname("host"), AppName("app"))
+	require.NoError(t, err)
+	defer h.Close()
+
+	require.NoError(t, h.Handle(context.Background(), testRecord(slog.LevelInfo, "message",
+		slog.Any("ids", []int{1, 2}))))
+	assert.Regexp(t, ` - \[slog ids="\[1 2\\]"\] message$`, read())
+}
+
+func TestHandler_Options(t *testing.T) {
+	addr, read := listenUDP(t)
+
+	h, err := New("udp", addr, Hostname(""), AppName("app"), MsgID("AUDIT"),
+		WithFacility(FacilityLocal3), DefaultSDID("attrs"), EnterpriseID("32473"))
+	require.NoError(t, err)
+	defer h.Close()
+
+	assert.False(t, h.Enabled(context.Background(), slog.LevelDebug))
+	assert.True(t, h.Enabled(context.Background(), slog.LevelInfo))
+
+	rec := slog.NewRecord(time.Time{}, slogx.LevelNotice, "message", 0)
+	rec.AddAttrs(slog.Int("a", 1), slog.Group("g", slog.Int("b", 2)))
+	require.NoError(t, h.Handle(context.Background(), rec))
+
+	assert.Equal(t, "<157>1 - - app "+strconv.Itoa(os.Getpid())+
+		` AUDIT [attrs@32473 a="1"][g@32473 b="2"] message`, read())
+}
+
+func TestHandler_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	frames := make(chan string, 10)
+	conns := make(chan net.Conn, 10)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- c
+			go func() {
+				r := bufio.NewReader(c)
+				for {
+					size, err := r.ReadString(' ')
+					if err != nil {
+						return
+					}
+					n, err := strconv.Atoi(strings.TrimSuffix(size, " "))
+					if err != nil {
+						return
+					}
+					frame := make([]byte, n)
+					if _, err = io.ReadFull(r, frame); err != nil {
+						return
+					}
+					frames <- string(frame)
+				}
+			}()
+		}
+	}()
+
+	h, err := New("tcp", ln.Addr().String(), Hostname("host"), AppName("app"))
+	require.NoError(t, err)
+	defer h.Close()
+
+	lg := slog.New(h)
+	lg.Info("first\nline", "k", "v")
+	lg.Info("second")
+
+	assert.True(t, strings.HasSuffix(<-frames, `[slog k="v"] first`+"\nline"))
+	assert.True(t, strings.HasSuffix(<-frames, " - second"))
+
+	// the server drops the connection, the handler reconnects
+	(<-conns).Close()
+	require.Eventually(t, func() bool {
+		lg.Info("after reconnect")
+		select {
+		case frame := <-frames:
+			return strings.HasSuffix(frame, "after reconnect")
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, conns, 1, "must reconnect")
+
+	require.NoError(t, h.Close())
+	assert.ErrorIs(t, h.Handle(context.Background(), testRecord(slog.LevelInfo, "closed")), net.ErrClosed)
+}
+
+func TestHandler_Unixgram(t *testing.T) {
+	dir, err := os.MkdirTemp("", "syslog") // t.TempDir may exceed the limit of the socket path
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "log.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err)
+	defer pc.Close()
+
+	h, err := New("unixgram", path, Hostname("host"), AppName("app"))
+	require.NoError(t, err)
+	defer h.Close()
+
+	slog.New(h).Warn("message")
+
+	buf := make([]byte, 1024)
+	require.NoError(t, pc.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Regexp(t, `^<12>1 \S+ host app \d+ - - message$`, string(buf[:n]))
+}
+
+func TestNew_Errors(t *testing.T) {
+	_, err := New("ip", "127.0.0.1")
+	assert.ErrorContains(t, err, `unsupported network "ip"`)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	_, err = New("tcp", addr)
+	assert.ErrorContains(t, err, "dial "+addr)
+}